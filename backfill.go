@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/blanket"
+	"github.com/colevoss/temperature-blanket/messenger"
+)
+
+/**
+ * Writes a CSV of every configured blanket's weather for a range of days:
+ *
+ *   ./main backfill -from 2023-01-01 -to 2023-06-30 -out 2023.csv
+ *
+ * Defaults to January 1st of this year through yesterday.
+ */
+func backfill(args []string) error {
+	now := time.Now()
+	defaultFrom := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	defaultTo := now.AddDate(0, 0, -1)
+
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	from := flags.String("from", defaultFrom.Format("2006-01-02"), "First day to get weather for")
+	to := flags.String("to", defaultTo.Format("2006-01-02"), "Last day to get weather for")
+	out := flags.String("out", "", "File to write the CSV to. Defaults to stdout")
+
+	flags.Parse(args)
+
+	first, err := time.Parse("2006-01-02", *from)
+
+	if err != nil {
+		return err
+	}
+
+	last, err := time.Parse("2006-01-02", *to)
+
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	config, err := loadConfig()
+
+	if err != nil {
+		return err
+	}
+
+	// Nothing is sent during a backfill
+	blankets, failures := newBlankets(ctx, config, messenger.NewMockMessenger())
+
+	// A CSV missing a blanket is easy to miss, so nothing is written
+	if len(failures) > 0 {
+		return failures
+	}
+
+	var w io.Writer = os.Stdout
+
+	if *out != "" {
+		file, err := os.Create(*out)
+
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		w = file
+	}
+
+	if err := blanket.WriteCSVHeader(w); err != nil {
+		return err
+	}
+
+	for _, b := range blankets {
+		weatherInfos, err := b.Backfill(ctx, first, last)
+
+		if err != nil {
+			return err
+		}
+
+		if err := b.WriteCSV(w, weatherInfos); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
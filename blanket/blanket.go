@@ -1,30 +1,134 @@
 package blanket
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"log"
-	"math"
 	"os"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/colevoss/temperature-blanket/messenger"
 	"github.com/colevoss/temperature-blanket/weather"
 )
 
+const DefaultMessage = "\nWeather for {{.Date}}:\n\u2600\ufe0f High: {{.High}}{{.Unit}}\n\u2744\ufe0f Low: {{.Low}}{{.Unit}}\n\U0001f600 Avg: {{.Average}}{{.Unit}}{{if .Colors}}\n\n{{.Colors}}{{end}}"
+
 type TemperatureBlanket struct {
+	// Used to tell blankets apart in logs and messages
+	Name string
+	// Numbers in the 1112223333 format. When empty TB_PHONE_NUMBERS is used
+	PhoneNumbers []string
+	// text/template for the message body. See MessageData for the fields
+	// available to it. When empty DefaultMessage is used
+	Message string
+	// Which definition of the day's average to report. Defaults to the mean
+	// of every reading
+	Average weather.AverageMethod
+	// Limits below which a day is considered incomplete
+	Coverage weather.CoverageThresholds
+	// What to do with an incomplete day. Defaults to IncompleteFlag
+	OnIncomplete IncompletePolicy
+	// Temperatures are shown in this unit. Defaults to fahrenheit
+	Unit weather.Unit
+	// How temperatures are rounded in messages, exports and colors. Defaults
+	// to DefaultRounding
+	Rounding TemperatureRounding
+	// Yarn colors for the day's temperatures. Colors are left out of
+	// messages when nil
+	Palette *Palette
+
 	weather   weather.Weather
 	messenger messenger.Messenger
 }
 
-func NewTemperatureBlanket(weather weather.Weather, messenger messenger.Messenger) *TemperatureBlanket {
+type IncompletePolicy string
+
+const (
+	// Send the message with a warning that the numbers may be off
+	IncompleteFlag IncompletePolicy = "flag"
+	// Do not send anything, DoIt returns an ErrIncompleteDay
+	IncompleteHold IncompletePolicy = "hold"
+	// Send the message as if the day were complete
+	IncompleteSend IncompletePolicy = "send"
+)
+
+func ParseIncompletePolicy(policy string) (IncompletePolicy, error) {
+	switch IncompletePolicy(policy) {
+	case "", IncompleteFlag:
+		return IncompleteFlag, nil
+	case IncompleteHold:
+		return IncompleteHold, nil
+	case IncompleteSend:
+		return IncompleteSend, nil
+	default:
+		return "", fmt.Errorf("unknown incomplete day policy %q, expected flag, hold or send", policy)
+	}
+}
+
+type ErrIncompleteDay struct {
+	Date     time.Time
+	Problems []string
+}
+
+func (e *ErrIncompleteDay) Error() string {
+	return fmt.Sprintf("weather for %s is incomplete: %s", e.Date.Format("Jan 2 2006"), strings.Join(e.Problems, ", "))
+}
+
+// Values available to a blanket's message template
+type MessageData struct {
+	Name    string
+	Date    string
+	High    string
+	Low     string
+	Average string
+	// Symbol of the unit temperatures are in, ex: °F
+	Unit string
+	// Inches, empty when the station has no rain gauge
+	Precipitation string
+	// Inches, empty when the station doesn't measure snow
+	Snowfall string
+	// Miles per hour, empty when the station has no anemometer
+	WindGust string
+	// Percent, empty when the station has no hygrometer
+	Humidity string
+	// Empty when the station has no hygrometer
+	DewPoint string
+	// Degrees the average was above normal, ex: +15 or -3. Empty when the
+	// provider doesn't report normals
+	Departure string
+	// Name of the yarn each temperature maps to. Empty without a palette or
+	// when the temperature is outside every band
+	HighYarn    string
+	LowYarn     string
+	AverageYarn string
+	// One line per temperature, ex: High 78°F → Goldenrod. Empty without a
+	// palette
+	Colors string
+	// Weather provider the numbers came from
+	Provider string
+	// Why the day is incomplete, empty when it is complete
+	Incomplete []string
+}
+
+func NewTemperatureBlanket(w weather.Weather, m messenger.Messenger) *TemperatureBlanket {
 	return &TemperatureBlanket{
-		weather,
-		messenger,
+		Coverage:     weather.DefaultCoverageThresholds,
+		OnIncomplete: IncompleteFlag,
+		Unit:         weather.Fahrenheit,
+		Rounding:     DefaultRounding,
+		weather:      w,
+		messenger:    m,
 	}
 }
 
 func (t *TemperatureBlanket) GetPhoneNumbers() ([]string, bool) {
+	if len(t.PhoneNumbers) > 0 {
+		return t.PhoneNumbers, true
+	}
+
 	envNumbers, present := os.LookupEnv("TB_PHONE_NUMBERS")
 
 	if !present {
@@ -37,28 +141,164 @@ func (t *TemperatureBlanket) GetPhoneNumbers() ([]string, bool) {
 	return numbers, true
 }
 
-func (t *TemperatureBlanket) DoIt() {
-	weatherInfo, err := t.weather.GetPreviousDaysWeatherInfo(time.Now())
+// The values shown for a day in messages and exports
+func (t *TemperatureBlanket) MessageData(weatherInfo *weather.WeatherInfo) *MessageData {
+	high, low, average := t.Temperatures(weatherInfo)
+
+	data := &MessageData{
+		Name:          t.Name,
+		Date:          weatherInfo.Date.Format("Jan 2 2006"),
+		High:          t.Rounding.High.format(high),
+		Low:           t.Rounding.Low.format(low),
+		Average:       t.Rounding.Average.format(average),
+		Unit:          t.Unit.Symbol(),
+		Precipitation: formatMeasure("%.2f", weatherInfo.Precipitation),
+		Snowfall:      formatMeasure("%.1f", weatherInfo.Snowfall),
+		WindGust:      formatMeasure("%.0f", weatherInfo.WindGust),
+		Humidity:      formatMeasure("%.0f", weatherInfo.Humidity),
+		DewPoint:      t.formatDewPoint(weatherInfo.DewPoint),
+		Departure:     t.formatDeparture(weatherInfo.Departure),
+		Provider:      weatherInfo.Provider,
+		Incomplete:    t.Coverage.Check(weatherInfo),
+	}
+
+	if t.Palette != nil {
+		data.HighYarn = yarn(t.Palette.Color(high))
+		data.LowYarn = yarn(t.Palette.Color(low))
+		data.AverageYarn = yarn(t.Palette.Color(average))
+
+		data.Colors = strings.Join([]string{
+			fmt.Sprintf("High %s%s → %s", data.High, data.Unit, colorName(data.HighYarn)),
+			fmt.Sprintf("Low %s%s → %s", data.Low, data.Unit, colorName(data.LowYarn)),
+			fmt.Sprintf("Avg %s%s → %s", data.Average, data.Unit, colorName(data.AverageYarn)),
+		}, "\n")
+	}
+
+	return data
+}
+
+func yarn(band *Band) string {
+	if band == nil {
+		return ""
+	}
+
+	return band.Yarn
+}
+
+func colorName(yarn string) string {
+	if yarn == "" {
+		return "no color"
+	}
+
+	return yarn
+}
+
+/**
+ * The day's high, low and average in the blanket's unit, rounded the way
+ * they are shown. Use these to pick the day's colors so they always match
+ * the message.
+ */
+func (t *TemperatureBlanket) Temperatures(weatherInfo *weather.WeatherInfo) (float64, float64, float64) {
+	high := t.Rounding.High.Round(t.Unit.FromFahrenheit(weatherInfo.High))
+	low := t.Rounding.Low.Round(t.Unit.FromFahrenheit(weatherInfo.Low))
+	average := t.Rounding.Average.Round(t.Unit.FromFahrenheit(weatherInfo.AverageFor(t.Average)))
+
+	return high, low, average
+}
+
+func (t *TemperatureBlanket) formatDewPoint(dewPoint *float64) string {
+	if dewPoint == nil {
+		return ""
+	}
+
+	return t.Rounding.Average.Format(t.Unit.FromFahrenheit(*dewPoint))
+}
+
+func (t *TemperatureBlanket) formatDeparture(departure *weather.Departure) string {
+	if departure == nil || departure.Average == nil {
+		return ""
+	}
+
+	formatted := t.Rounding.Average.Format(t.Unit.DifferenceFromFahrenheit(*departure.Average))
+
+	if !strings.HasPrefix(formatted, "-") {
+		formatted = "+" + formatted
+	}
+
+	return formatted
+}
+
+// Formats a measure that may be absent as an empty string
+func formatMeasure(format string, value *float64) string {
+	if value == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(format, *value)
+}
+
+func (t *TemperatureBlanket) FormatMessage(weatherInfo *weather.WeatherInfo) (string, error) {
+	messageTemplate := t.Message
+
+	if messageTemplate == "" {
+		messageTemplate = DefaultMessage
+	}
+
+	tmpl, err := template.New(t.Name).Parse(messageTemplate)
 
 	if err != nil {
-		log.Printf("Bad thigns: %s", err)
+		return "", err
 	}
 
-	formattedDate := weatherInfo.Date.Format("Jan 2 2006")
+	data := t.MessageData(weatherInfo)
+
+	var message bytes.Buffer
+	err = tmpl.Execute(&message, data)
 
-	message := fmt.Sprintf(
-		"\nWeather for %s:\n\u2600\ufe0f High: %.0f°\n\u2744\ufe0f Low: %.0f°\n\U0001f600 Avg: %.0f°",
-		formattedDate,
-		math.Ceil(weatherInfo.High),
-		math.Ceil(weatherInfo.Low),
-		math.Ceil(weatherInfo.Average),
-	)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data.Incomplete) > 0 && t.OnIncomplete != IncompleteSend {
+		fmt.Fprintf(&message, "\n\u26a0\ufe0f Incomplete data: %s", strings.Join(data.Incomplete, ", "))
+	}
+
+	return message.String(), nil
+}
+
+// Sends yesterday's weather, in the weather provider's timezone
+func (t *TemperatureBlanket) DoIt(ctx context.Context) error {
+	yesterday := weather.Yesterday(ctx, t.weather, time.Now())
+	weatherInfo, err := t.weather.GetDailyWeather(ctx, yesterday)
+
+	if err != nil {
+		log.Printf("Could not get weather for %s: %s", t.Name, err)
+		return err
+	}
+
+	log.Printf("Weather for %s provided by %s", t.Name, weatherInfo.Provider)
+
+	if problems := t.Coverage.Check(weatherInfo); len(problems) > 0 {
+		err := &ErrIncompleteDay{Date: weatherInfo.Date, Problems: problems}
+		log.Printf("%s: %s", t.Name, err)
+
+		if t.OnIncomplete == IncompleteHold {
+			return err
+		}
+	}
+
+	message, err := t.FormatMessage(weatherInfo)
+
+	if err != nil {
+		log.Printf("Could not format message for %s: %s", t.Name, err)
+		return err
+	}
 
 	numbers, present := t.GetPhoneNumbers()
 
 	if !present {
 		log.Printf("No numbers present. Nothing to send")
-		return
+		return nil
 	}
 
 	for _, number := range numbers {
@@ -68,4 +308,6 @@ func (t *TemperatureBlanket) DoIt() {
 			log.Printf("Error sending message %s", err)
 		}
 	}
+
+	return nil
 }
@@ -1,19 +1,67 @@
 package blanket
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/colevoss/temperature-blanket/messenger"
 	"github.com/colevoss/temperature-blanket/synoptic"
+	"github.com/colevoss/temperature-blanket/weather"
 	// "github.com/colevoss/temperature-blanket/twilio"
 )
 
 func TestBlanket(t *testing.T) {
-	synopticApi := synoptic.New()
+	synopticApi := synoptic.New(synoptic.DefaultStationId)
 	// m := twilio.New()
 	m := messenger.NewMockMessenger()
 
 	blanket := NewTemperatureBlanket(synopticApi, m)
 
-	blanket.DoIt()
+	blanket.DoIt(context.Background())
+}
+
+func TestFormatMessageUnit(t *testing.T) {
+	departure := 18.0
+
+	blanket := NewTemperatureBlanket(nil, nil)
+	blanket.Unit = weather.Celsius
+	blanket.Message = "{{.High}}{{.Unit}}/{{.Low}}{{.Unit}}/{{.Average}}{{.Unit}} {{.Departure}}"
+
+	message, err := blanket.FormatMessage(&weather.WeatherInfo{
+		Date:      time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC),
+		High:      50,
+		Low:       14,
+		Average:   32,
+		Departure: &weather.Departure{Average: &departure},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message != "10°C/-10°C/0°C +10" {
+		t.Errorf("Unexpected message %q", message)
+	}
+}
+
+func TestDefaultMessageUnit(t *testing.T) {
+	blanket := NewTemperatureBlanket(nil, nil)
+	blanket.Unit = weather.Kelvin
+
+	message, err := blanket.FormatMessage(&weather.WeatherInfo{
+		Date:    time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC),
+		High:    50,
+		Low:     14,
+		Average: 32,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(message, "High: 284K") || !strings.Contains(message, "Low: 264K") {
+		t.Errorf("Expected the default message to show the unit, got %q", message)
+	}
 }
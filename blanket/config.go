@@ -0,0 +1,147 @@
+package blanket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+// A file describing every blanket to process in a single run
+type Config struct {
+	Blankets []*BlanketConfig `json:"blankets"`
+}
+
+type BlanketConfig struct {
+	Name string `json:"name"`
+	// Synoptic station id. When empty the nearest station to Location or
+	// Latitude/Longitude is used
+	StationId string `json:"stationId"`
+	// IANA timezone the day is computed in. Defaults to the station's timezone
+	Timezone string `json:"timezone"`
+	// ZIP code or city name used to discover a station
+	Location string `json:"location"`
+	// Optional ISO-3166-1 alpha2 code the location is looked up in, ex: US.
+	// Anywhere in the world when empty
+	Country   string   `json:"country"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	// Numbers in the 1112223333 format
+	PhoneNumbers []string `json:"phoneNumbers"`
+	// text/template for the message body. Defaults to DefaultMessage
+	Message string `json:"message"`
+	// Definition of the day's average: mean (default), time-weighted or nws
+	Average string `json:"average"`
+	// Unit temperatures are shown in: F (default), C or K
+	Unit string `json:"unit"`
+	// How each temperature is rounded. Defaults to DefaultRounding
+	Rounding *RoundingConfig `json:"rounding"`
+	// Path to a palette JSON file mapping temperatures to yarn colors
+	Palette string `json:"palette"`
+	// Weather providers tried in order until one answers. Defaults to synoptic
+	Providers []*ProviderConfig `json:"providers"`
+	// Limits below which a day is incomplete. Defaults to
+	// weather.DefaultCoverageThresholds
+	Coverage *CoverageConfig `json:"coverage"`
+	// What to do with an incomplete day: flag (default), hold or send
+	OnIncomplete string `json:"onIncomplete"`
+	// Directory finished days are cached in so they are only fetched once
+	CacheDir string `json:"cacheDir"`
+}
+
+type ProviderConfig struct {
+	// Which provider to use, ex: synoptic
+	Type string `json:"type"`
+	// File or URL the provider reads from, ex: a NOAA .dly file or METAR feed
+	Path string `json:"path"`
+	// Provider specific station id, ex: a GHCN-Daily id like USW00014939 or
+	// a METAR station like KLNK. For synoptic it is used over the blanket's
+	StationId string `json:"stationId"`
+	// Values in the file are celsius rather than fahrenheit
+	Metric bool `json:"metric"`
+	// How synoptic summarizes days: timeseries (default) or statistics
+	Strategy string `json:"strategy"`
+	// Have synoptic also summarize statistics days from every reading and
+	// log where the two disagree
+	CrossCheck bool `json:"crossCheck"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	contents, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	err = json.Unmarshal(contents, &config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Blankets) == 0 {
+		return nil, errors.New("blanket: config does not define any blankets")
+	}
+
+	// Failures are reported by name, so each must be unique
+	names := map[string]bool{}
+
+	for _, blanketConfig := range config.Blankets {
+		if blanketConfig.Name == "" {
+			continue
+		}
+
+		if names[blanketConfig.Name] {
+			return nil, fmt.Errorf("blanket: more than one blanket is named %q", blanketConfig.Name)
+		}
+
+		names[blanketConfig.Name] = true
+	}
+
+	return &config, nil
+}
+
+type CoverageConfig struct {
+	MinObservations int     `json:"minObservations"`
+	MaxGapMinutes   int     `json:"maxGapMinutes"`
+	MinPercent      float64 `json:"minPercent"`
+}
+
+func (c *CoverageConfig) Thresholds() weather.CoverageThresholds {
+	return weather.CoverageThresholds{
+		MinObservations: c.MinObservations,
+		MaxGap:          time.Minute * time.Duration(c.MaxGapMinutes),
+		MinPercent:      c.MinPercent,
+	}
+}
+
+// Each is a mode optionally followed by decimals, ex: "nearest" or
+// "half-even:1". See ParseRounding
+type RoundingConfig struct {
+	High    string `json:"high"`
+	Low     string `json:"low"`
+	Average string `json:"average"`
+}
+
+func (c *RoundingConfig) Rounding() (TemperatureRounding, error) {
+	var rounding TemperatureRounding
+	var err error
+
+	if rounding.High, err = ParseRounding(c.High); err != nil {
+		return rounding, err
+	}
+
+	if rounding.Low, err = ParseRounding(c.Low); err != nil {
+		return rounding, err
+	}
+
+	if rounding.Average, err = ParseRounding(c.Average); err != nil {
+		return rounding, err
+	}
+
+	return rounding, nil
+}
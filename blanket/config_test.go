@@ -0,0 +1,23 @@
+package blanket
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigDuplicateNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{"blankets": [{"name": "Lincoln"}, {}, {}, {"name": "Lincoln"}]}`
+
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadConfig(path)
+
+	if err == nil || !strings.Contains(err.Error(), `"Lincoln"`) {
+		t.Errorf("Expected duplicate names to be rejected, got %v", err)
+	}
+}
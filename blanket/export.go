@@ -0,0 +1,68 @@
+package blanket
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+/**
+ * Gets the weather for every day from first through last, ex: to catch up on
+ * the rows of a blanket started mid-year
+ */
+func (t *TemperatureBlanket) Backfill(ctx context.Context, first time.Time, last time.Time) ([]*weather.WeatherInfo, error) {
+	return t.weather.GetDailyRange(ctx, first, last)
+}
+
+// Writes the header for rows written by WriteCSV
+func WriteCSVHeader(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"blanket", "date", "high", "low", "average", "provider", "precipitation", "snowfall", "wind_gust", "humidity", "dew_point", "departure", "high_yarn", "low_yarn", "average_yarn"})
+
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// Writes one row per day with the same values the messages use
+func (t *TemperatureBlanket) WriteCSV(w io.Writer, weatherInfos []*weather.WeatherInfo) error {
+	writer := csv.NewWriter(w)
+
+	for _, weatherInfo := range weatherInfos {
+		data := t.MessageData(weatherInfo)
+
+		err := writer.Write([]string{
+			t.Name,
+			weatherInfo.Date.Format("2006-01-02"),
+			data.High,
+			data.Low,
+			data.Average,
+			data.Provider,
+			data.Precipitation,
+			data.Snowfall,
+			data.WindGust,
+			data.Humidity,
+			data.DewPoint,
+			data.Departure,
+			data.HighYarn,
+			data.LowYarn,
+			data.AverageYarn,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
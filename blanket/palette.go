@@ -0,0 +1,152 @@
+package blanket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+/**
+ * A yarn for temperatures from Min up to, but not including, Max. The
+ * coldest band can leave out Min and the warmest can leave out Max to catch
+ * everything beyond them.
+ */
+type Band struct {
+	Min   *float64 `json:"min"`
+	Max   *float64 `json:"max"`
+	Yarn  string   `json:"yarn"`
+	Brand string   `json:"brand"`
+	// Ex: #DAA520
+	Hex string `json:"hex"`
+}
+
+func (b *Band) Contains(temp float64) bool {
+	return (b.Min == nil || temp >= *b.Min) && (b.Max == nil || temp < *b.Max)
+}
+
+func (b *Band) String() string {
+	bound := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+
+		return fmt.Sprintf("%g", *value)
+	}
+
+	return fmt.Sprintf("%s (%s..%s)", b.Yarn, bound(b.Min), bound(b.Max))
+}
+
+/**
+ * The yarns a blanket is knit with, ordered from the coldest band to the
+ * warmest. Temperatures are matched after they are converted to the
+ * blanket's unit and rounded, so the color always agrees with the message.
+ */
+type Palette struct {
+	// Unit the bands are in. Must match the blanket's, defaults to F
+	Unit  string  `json:"unit"`
+	Bands []*Band `json:"bands"`
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Reads and validates a palette from a JSON file
+func LoadPalette(path string) (*Palette, error) {
+	contents, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var palette Palette
+	err = json.Unmarshal(contents, &palette)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := palette.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &palette, nil
+}
+
+/**
+ * Checks every band has a yarn and a valid range and that the bands go from
+ * coldest to warmest with no gaps or overlaps between them
+ */
+func (p *Palette) Validate() error {
+	if len(p.Bands) == 0 {
+		return errors.New("palette does not define any bands")
+	}
+
+	if _, err := weather.ParseUnit(p.Unit); err != nil {
+		return err
+	}
+
+	problems := []string{}
+
+	for i, band := range p.Bands {
+		name := fmt.Sprintf("band %d", i+1)
+
+		if band.Yarn == "" {
+			problems = append(problems, name+" has no yarn")
+		} else {
+			name = fmt.Sprintf("band %d %s", i+1, band)
+		}
+
+		if band.Hex != "" && !hexColor.MatchString(band.Hex) {
+			problems = append(problems, fmt.Sprintf("%s has an invalid hex %q, expected #RRGGBB", name, band.Hex))
+		}
+
+		if band.Min != nil && band.Max != nil && *band.Min >= *band.Max {
+			problems = append(problems, fmt.Sprintf("%s has a min that is not below its max", name))
+		}
+
+		if band.Min == nil && i > 0 {
+			problems = append(problems, fmt.Sprintf("%s has no min, only the coldest band can leave it out", name))
+		}
+
+		if band.Max == nil && i < len(p.Bands)-1 {
+			problems = append(problems, fmt.Sprintf("%s has no max, only the warmest band can leave it out", name))
+		}
+
+		if i == 0 || band.Min == nil {
+			continue
+		}
+
+		previous := p.Bands[i-1]
+
+		switch {
+		case previous.Min != nil && *band.Min < *previous.Min:
+			problems = append(problems, fmt.Sprintf("%s is out of order, bands must go from coldest to warmest", name))
+		case previous.Max == nil:
+		case *band.Min < *previous.Max:
+			problems = append(problems, fmt.Sprintf("%s overlaps the band before it, which ends at %g", name, *previous.Max))
+		case *band.Min > *previous.Max:
+			problems = append(problems, fmt.Sprintf("%s leaves a gap after the band before it, which ends at %g", name, *previous.Max))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid palette: %s", strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// The band the temperature falls in, nil when it is outside every band
+func (p *Palette) Color(temp float64) *Band {
+	for _, band := range p.Bands {
+		if band.Contains(temp) {
+			return band
+		}
+	}
+
+	return nil
+}
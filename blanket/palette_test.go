@@ -0,0 +1,107 @@
+package blanket
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+const testPalette = `{
+	"unit": "F",
+	"bands": [
+		{"max": 60, "yarn": "Navy", "brand": "Red Heart", "hex": "#1F2A44"},
+		{"min": 60, "max": 70, "yarn": "Sage", "brand": "Lion Brand", "hex": "#9CAF88"},
+		{"min": 70, "max": 78, "yarn": "Teal", "brand": "Lion Brand", "hex": "#008080"},
+		{"min": 78, "yarn": "Goldenrod", "brand": "Red Heart", "hex": "#DAA520"}
+	]
+}`
+
+func parsePalette(t *testing.T, contents string) *Palette {
+	var palette Palette
+
+	if err := json.Unmarshal([]byte(contents), &palette); err != nil {
+		t.Fatal(err)
+	}
+
+	return &palette
+}
+
+func TestLoadPalette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "palette.json")
+
+	if err := os.WriteFile(path, []byte(testPalette), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	palette, err := LoadPalette(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[float64]string{-20: "Navy", 59.9: "Navy", 60: "Sage", 77: "Teal", 78: "Goldenrod", 120: "Goldenrod"}
+
+	for temp, expected := range tests {
+		if band := palette.Color(temp); band == nil || band.Yarn != expected {
+			t.Errorf("Expected %g to be %s, got %v", temp, expected, band)
+		}
+	}
+}
+
+func TestPaletteValidate(t *testing.T) {
+	tests := []struct {
+		palette  string
+		expected string
+	}{
+		{`{"bands": [{"max": 60, "yarn": "Navy"}, {"min": 65, "yarn": "Sage"}]}`, "leaves a gap"},
+		{`{"bands": [{"max": 60, "yarn": "Navy"}, {"min": 55, "yarn": "Sage"}]}`, "overlaps"},
+		{`{"bands": [{"min": 70, "max": 80, "yarn": "Teal"}, {"min": 60, "max": 70, "yarn": "Sage"}]}`, "out of order"},
+		{`{"bands": [{"min": 70, "max": 60, "yarn": "Teal"}]}`, "not below its max"},
+		{`{"bands": [{"max": 60, "yarn": "Navy"}, {"max": 70, "yarn": "Sage"}]}`, "only the coldest band"},
+		{`{"bands": [{"yarn": "Navy"}, {"min": 70, "yarn": "Sage"}]}`, "only the warmest band"},
+		{`{"bands": [{"max": 60}]}`, "has no yarn"},
+		{`{"bands": [{"max": 60, "yarn": "Navy", "hex": "navy"}]}`, "invalid hex"},
+		{`{"bands": []}`, "does not define any bands"},
+		{`{"unit": "R", "bands": [{"yarn": "Navy"}]}`, "unknown"},
+	}
+
+	for _, test := range tests {
+		err := parsePalette(t, test.palette).Validate()
+
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("Expected %q for %s, got %v", test.expected, test.palette, err)
+		}
+	}
+
+	if err := parsePalette(t, testPalette).Validate(); err != nil {
+		t.Errorf("Expected the palette to be valid, got %s", err)
+	}
+}
+
+func TestFormatMessageColors(t *testing.T) {
+	blanket := NewTemperatureBlanket(nil, nil)
+	blanket.Palette = parsePalette(t, testPalette)
+
+	message, err := blanket.FormatMessage(&weather.WeatherInfo{
+		Date:    time.Date(2023, 7, 10, 0, 0, 0, 0, time.UTC),
+		High:    77.2,
+		Low:     59.5,
+		Average: 69.1,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Colors are picked from the rounded temperatures shown
+	expected := "High 78°F → Goldenrod\nLow 60°F → Sage\nAvg 70°F → Teal"
+
+	if !strings.HasSuffix(message, expected) {
+		t.Errorf("Expected message to end with %q, got %q", expected, message)
+	}
+}
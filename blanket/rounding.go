@@ -0,0 +1,111 @@
+package blanket
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type RoundingMode string
+
+const (
+	// Halves round away from zero, 31.5 becomes 32
+	RoundNearest RoundingMode = "nearest"
+	// Halves round to the even neighbor, 31.5 becomes 32 and 32.5 becomes 32
+	RoundHalfEven RoundingMode = "half-even"
+	RoundFloor    RoundingMode = "floor"
+	// 31.01 becomes 32. The default, what blankets have always done
+	RoundCeil RoundingMode = "ceil"
+	// Drops the fraction, -3.7 becomes -3
+	RoundTruncate RoundingMode = "truncate"
+)
+
+// How a temperature is rounded before it is shown or matched to a color
+type Rounding struct {
+	Mode RoundingMode
+	// Digits kept after the decimal point
+	Decimals int
+}
+
+/**
+ * Parses a mode optionally followed by the decimals to keep, ex: "nearest"
+ * or "half-even:1". Defaults to RoundCeil with no decimals.
+ */
+func ParseRounding(rounding string) (Rounding, error) {
+	mode, decimals, hasDecimals := strings.Cut(strings.TrimSpace(rounding), ":")
+
+	r := Rounding{Mode: RoundingMode(mode)}
+
+	switch r.Mode {
+	case "":
+		r.Mode = RoundCeil
+	case RoundNearest, RoundHalfEven, RoundFloor, RoundCeil, RoundTruncate:
+	default:
+		return r, fmt.Errorf("unknown rounding %q, expected nearest, half-even, floor, ceil or truncate", mode)
+	}
+
+	if hasDecimals {
+		places, err := strconv.Atoi(decimals)
+
+		if err != nil || places < 0 {
+			return r, fmt.Errorf("invalid rounding decimals %q", decimals)
+		}
+
+		r.Decimals = places
+	}
+
+	return r, nil
+}
+
+func (r Rounding) Round(value float64) float64 {
+	scale := math.Pow(10, float64(r.Decimals))
+	// Drop floating point noise so 0.3 * 10 isn't ceiled to 4
+	scaled := math.Round(value*scale*1e9) / 1e9
+
+	switch r.Mode {
+	case RoundNearest:
+		scaled = math.Round(scaled)
+	case RoundHalfEven:
+		scaled = math.RoundToEven(scaled)
+	case RoundFloor:
+		scaled = math.Floor(scaled)
+	case RoundTruncate:
+		scaled = math.Trunc(scaled)
+	default:
+		scaled = math.Ceil(scaled)
+	}
+
+	rounded := scaled / scale
+
+	// Don't show -0 for temperatures just below zero
+	if rounded == 0 {
+		return 0
+	}
+
+	return rounded
+}
+
+// Rounds the value and formats it with the rounding's decimals
+func (r Rounding) Format(value float64) string {
+	return r.format(r.Round(value))
+}
+
+// Formats a value that has already been rounded
+func (r Rounding) format(rounded float64) string {
+	return strconv.FormatFloat(rounded, 'f', r.Decimals, 64)
+}
+
+// Rounding for each of the day's temperatures
+type TemperatureRounding struct {
+	High Rounding
+	Low  Rounding
+	// Also used for the dew point and departure from normal
+	Average Rounding
+}
+
+var DefaultRounding = TemperatureRounding{
+	High:    Rounding{Mode: RoundCeil},
+	Low:     Rounding{Mode: RoundCeil},
+	Average: Rounding{Mode: RoundCeil},
+}
@@ -0,0 +1,67 @@
+package blanket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+func TestRounding(t *testing.T) {
+	for _, test := range []struct {
+		rounding string
+		value    float64
+		expected string
+	}{
+		{"", 31.01, "32"},
+		{"nearest", 31.01, "31"},
+		{"nearest", 31.5, "32"},
+		{"nearest", -0.4, "0"},
+		{"half-even", 32.5, "32"},
+		{"half-even", 31.5, "32"},
+		{"floor", -3.2, "-4"},
+		{"truncate", -3.7, "-3"},
+		{"ceil:1", 0.3, "0.3"},
+		{"half-even:1", 2.25, "2.2"},
+		{"nearest:2", 1.005, "1.01"},
+	} {
+		rounding, err := ParseRounding(test.rounding)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := rounding.Format(test.value); got != test.expected {
+			t.Errorf("Expected %q to round %v to %s, got %s", test.rounding, test.value, test.expected, got)
+		}
+	}
+
+	for _, invalid := range []string{"up", "nearest:x", "nearest:-1"} {
+		if _, err := ParseRounding(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestMessageDataRounding(t *testing.T) {
+	config := &RoundingConfig{High: "nearest", Low: "floor", Average: "nearest:1"}
+	rounding, err := config.Rounding()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blanket := NewTemperatureBlanket(nil, nil)
+	blanket.Rounding = rounding
+
+	data := blanket.MessageData(&weather.WeatherInfo{
+		Date:    time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC),
+		High:    31.01,
+		Low:     12.9,
+		Average: 21.96,
+	})
+
+	if data.High != "31" || data.Low != "12" || data.Average != "22.0" {
+		t.Errorf("Unexpected temperatures %s/%s/%s", data.High, data.Low, data.Average)
+	}
+}
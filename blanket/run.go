@@ -0,0 +1,73 @@
+package blanket
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Returned from RunAll when one or more blankets failed. Keyed by blanket name
+type RunError map[string]error
+
+func (e RunError) Error() string {
+	names := []string{}
+
+	for name := range e {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	failures := []string{}
+
+	for _, name := range names {
+		failures = append(failures, fmt.Sprintf("%s: %s", name, e[name]))
+	}
+
+	return fmt.Sprintf("%d blanket(s) failed: %s", len(e), strings.Join(failures, "; "))
+}
+
+/**
+ * Runs every blanket concurrently. A blanket that fails does not stop the
+ * others; all failures are returned together as a RunError.
+ */
+func RunAll(ctx context.Context, blankets []*TemperatureBlanket) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	failures := RunError{}
+
+	for i, blanket := range blankets {
+		wg.Add(1)
+
+		go func(i int, blanket *TemperatureBlanket) {
+			defer wg.Done()
+
+			err := blanket.DoIt(ctx)
+
+			if err == nil {
+				return
+			}
+
+			name := blanket.Name
+
+			if name == "" {
+				name = fmt.Sprintf("blanket %d", i+1)
+			}
+
+			mu.Lock()
+			failures[name] = err
+			mu.Unlock()
+		}(i, blanket)
+	}
+
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return failures
+	}
+
+	return nil
+}
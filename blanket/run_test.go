@@ -0,0 +1,119 @@
+package blanket
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+type fakeWeather struct {
+	info *weather.WeatherInfo
+	err  error
+}
+
+func (f *fakeWeather) GetDailyWeather(ctx context.Context, date time.Time) (*weather.WeatherInfo, error) {
+	return f.info, f.err
+}
+
+func (f *fakeWeather) GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*weather.WeatherInfo, error) {
+	return weather.EachDay(ctx, from, to, time.UTC, f.GetDailyWeather)
+}
+
+type recordingMessenger struct {
+	mu       sync.Mutex
+	messages map[string]string
+}
+
+func (r *recordingMessenger) SendMessage(to string, message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages[to] = message
+
+	return nil
+}
+
+func TestRunAll(t *testing.T) {
+	m := &recordingMessenger{messages: map[string]string{}}
+
+	lincoln := NewTemperatureBlanket(&fakeWeather{info: &weather.WeatherInfo{
+		Date:    time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC),
+		High:    55.4,
+		Low:     24.8,
+		Average: 35.2,
+	}}, m)
+	lincoln.Name = "Lincoln"
+	lincoln.PhoneNumbers = []string{"1112223333"}
+	lincoln.Message = "{{.Name}} {{.Date}} {{.High}}/{{.Low}}/{{.Average}}"
+
+	denver := NewTemperatureBlanket(&fakeWeather{err: errors.New("boom")}, m)
+	denver.Name = "Denver"
+	denver.PhoneNumbers = []string{"4445556666"}
+
+	err := RunAll(context.Background(), []*TemperatureBlanket{lincoln, denver})
+
+	var runErr RunError
+
+	if !errors.As(err, &runErr) || len(runErr) != 1 || runErr["Denver"] == nil {
+		t.Fatalf("Expected Denver to fail, got %v", err)
+	}
+
+	message := m.messages["+11112223333"]
+
+	if message != "Lincoln Jan 10 2023 56/25/36" {
+		t.Errorf("Unexpected message %q", message)
+	}
+
+	if _, sent := m.messages["+14445556666"]; sent {
+		t.Errorf("Did not expect a message for a failed blanket")
+	}
+
+	if !strings.Contains(err.Error(), "Denver: boom") {
+		t.Errorf("Unexpected error %s", err)
+	}
+}
+
+func TestIncompleteDay(t *testing.T) {
+	m := &recordingMessenger{messages: map[string]string{}}
+
+	sparse := &weather.WeatherInfo{
+		Date:     time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC),
+		High:     40,
+		Low:      30,
+		Average:  35,
+		Coverage: &weather.Coverage{Observations: 3, LargestGap: time.Hour * 10, Percent: 12.5},
+	}
+
+	flagged := NewTemperatureBlanket(&fakeWeather{info: sparse}, m)
+	flagged.PhoneNumbers = []string{"1112223333"}
+	flagged.Message = "{{.High}}/{{.Low}}"
+
+	if err := flagged.DoIt(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "40/30\n⚠️ Incomplete data: only 3 observations, a 10h0m0s gap in observations, only 12% of the day covered"
+
+	if m.messages["+11112223333"] != expected {
+		t.Errorf("Unexpected message %q", m.messages["+11112223333"])
+	}
+
+	held := NewTemperatureBlanket(&fakeWeather{info: sparse}, m)
+	held.PhoneNumbers = []string{"4445556666"}
+	held.OnIncomplete = IncompleteHold
+
+	var incomplete *ErrIncompleteDay
+
+	if err := held.DoIt(context.Background()); !errors.As(err, &incomplete) {
+		t.Errorf("Expected ErrIncompleteDay, got %v", err)
+	}
+
+	if _, sent := m.messages["+14445556666"]; sent {
+		t.Errorf("Did not expect a message for a held day")
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/colevoss/temperature-blanket/pws"
+)
+
+/**
+ * Receives uploads from a personal weather station and appends them to the
+ * file at TB_PWS_STORE for the pws weather provider to read.
+ */
+func main() {
+	addr := os.Getenv("TB_PWS_ADDR")
+
+	if addr == "" {
+		addr = ":8080"
+	}
+
+	path := os.Getenv("TB_PWS_STORE")
+
+	if path == "" {
+		path = "pws.jsonl"
+	}
+
+	receiver := pws.NewReceiver(pws.NewFileStore(path), os.Getenv("TB_PWS_KEY"))
+
+	log.Printf("Receiving station uploads on %s, storing them in %s", addr, path)
+
+	log.Fatal(http.ListenAndServe(addr, receiver))
+}
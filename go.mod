@@ -3,8 +3,11 @@ module github.com/colevoss/temperature-blanket
 go 1.19
 
 require (
-	github.com/aws/aws-lambda-go v1.37.0 // indirect
+	github.com/aws/aws-lambda-go v1.37.0
+	github.com/twilio/twilio-go v1.3.1
+)
+
+require (
 	github.com/golang/mock v1.6.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/twilio/twilio-go v1.3.1 // indirect
 )
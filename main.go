@@ -2,22 +2,197 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/colevoss/temperature-blanket/blanket"
-	"github.com/colevoss/temperature-blanket/synoptic"
+	"github.com/colevoss/temperature-blanket/messenger"
 	"github.com/colevoss/temperature-blanket/twilio"
+	"github.com/colevoss/temperature-blanket/weather"
 )
 
-func Handler(ctx context.Context) {
-	synopticApi := synoptic.New()
-	m := twilio.New()
+/**
+ * Loads the blanket definitions from the file at TB_CONFIG. Without one, a
+ * single blanket is configured from the environment.
+ */
+func loadConfig() (*blanket.Config, error) {
+	if path, present := os.LookupEnv("TB_CONFIG"); present {
+		return blanket.LoadConfig(path)
+	}
 
-	blanket := blanket.NewTemperatureBlanket(synopticApi, m)
+	blanketConfig := &blanket.BlanketConfig{
+		StationId:    os.Getenv("TB_STATION_ID"),
+		Timezone:     os.Getenv("TB_TIMEZONE"),
+		Location:     os.Getenv("TB_LOCATION"),
+		Country:      os.Getenv("TB_COUNTRY"),
+		CacheDir:     os.Getenv("TB_CACHE_DIR"),
+		Average:      os.Getenv("TB_AVERAGE"),
+		Unit:         os.Getenv("TB_UNIT"),
+		Palette:      os.Getenv("TB_PALETTE"),
+		OnIncomplete: os.Getenv("TB_ON_INCOMPLETE"),
+	}
 
-	blanket.DoIt()
+	if rounding, present := os.LookupEnv("TB_ROUNDING"); present {
+		blanketConfig.Rounding = &blanket.RoundingConfig{
+			High:    rounding,
+			Low:     rounding,
+			Average: rounding,
+		}
+	}
+
+	latitude, latErr := strconv.ParseFloat(os.Getenv("TB_LATITUDE"), 64)
+	longitude, lonErr := strconv.ParseFloat(os.Getenv("TB_LONGITUDE"), 64)
+
+	if latErr == nil && lonErr == nil {
+		blanketConfig.Latitude = &latitude
+		blanketConfig.Longitude = &longitude
+	}
+
+	if providers, present := os.LookupEnv("TB_PROVIDERS"); present {
+		for _, provider := range strings.Split(providers, ",") {
+			blanketConfig.Providers = append(blanketConfig.Providers, &blanket.ProviderConfig{
+				Type: strings.TrimSpace(provider),
+			})
+		}
+	}
+
+	return &blanket.Config{
+		Blankets: []*blanket.BlanketConfig{blanketConfig},
+	}, nil
+}
+
+/**
+ * Failures are logged rather than returned. Lambda retries a failed scheduled
+ * run, which would text every blanket that succeeded a second time.
+ */
+func Handler(ctx context.Context) error {
+	config, err := loadConfig()
+
+	if err != nil {
+		log.Printf("Could not load config: %s", err)
+		return err
+	}
+
+	blankets, failures := newBlankets(ctx, config, twilio.New())
+
+	var runErr blanket.RunError
+
+	if errors.As(blanket.RunAll(ctx, blankets), &runErr) {
+		for name, err := range runErr {
+			failures[name] = err
+		}
+	}
+
+	if len(failures) > 0 {
+		log.Println(failures.Error())
+	}
+
+	return nil
+}
+
+/**
+ * Builds every configured blanket. A blanket that cannot be set up is left
+ * out and its error is returned so the others can still run.
+ */
+func newBlankets(ctx context.Context, config *blanket.Config, m messenger.Messenger) ([]*blanket.TemperatureBlanket, blanket.RunError) {
+	blankets := []*blanket.TemperatureBlanket{}
+	failures := blanket.RunError{}
+
+	for i, blanketConfig := range config.Blankets {
+		// Named once so setup and run failures for a blanket share a key
+		name := blanketConfig.Name
+
+		if name == "" {
+			name = fmt.Sprintf("blanket %d", i+1)
+		}
+
+		b, err := newBlanket(ctx, blanketConfig, m)
+
+		if err != nil {
+			log.Printf("Could not configure %s: %s", name, err)
+			failures[name] = err
+
+			continue
+		}
+
+		b.Name = name
+		blankets = append(blankets, b)
+	}
+
+	return blankets, failures
+}
+
+func newBlanket(ctx context.Context, blanketConfig *blanket.BlanketConfig, m messenger.Messenger) (*blanket.TemperatureBlanket, error) {
+	w, err := newWeather(ctx, blanketConfig)
+
+	if err != nil {
+		return nil, err
+	}
+
+	average, err := weather.ParseAverageMethod(blanketConfig.Average)
+
+	if err != nil {
+		return nil, err
+	}
+
+	unit, err := weather.ParseUnit(blanketConfig.Unit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b := blanket.NewTemperatureBlanket(w, m)
+	b.PhoneNumbers = blanketConfig.PhoneNumbers
+	b.Message = blanketConfig.Message
+	b.Average = average
+	b.Unit = unit
+
+	b.OnIncomplete, err = blanket.ParseIncompletePolicy(blanketConfig.OnIncomplete)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if blanketConfig.Coverage != nil {
+		b.Coverage = blanketConfig.Coverage.Thresholds()
+	}
+
+	if blanketConfig.Rounding != nil {
+		b.Rounding, err = blanketConfig.Rounding.Rounding()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if blanketConfig.Palette != "" {
+		b.Palette, err = blanket.LoadPalette(blanketConfig.Palette)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if paletteUnit, _ := weather.ParseUnit(b.Palette.Unit); paletteUnit != b.Unit {
+			return nil, fmt.Errorf("palette %s is in %s but %s is shown in %s", blanketConfig.Palette, paletteUnit.Symbol(), blanketConfig.Name, b.Unit.Symbol())
+		}
+	}
+
+	return b, nil
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		if err := backfill(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
 	lambda.Start(Handler)
 }
@@ -0,0 +1,257 @@
+package metar
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+// How close to the end of the day a report's 24 hour max/min group has to be
+// to be considered to cover that day. The group is sent with the report
+// closest to midnight local standard time, which is 1 AM during daylight
+// saving time.
+const dayGroupWindow = time.Minute * 90
+
+// Used when the source is a URL so a hung connection can't stall a run
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+/**
+ * Computes daily weather from raw METAR/SPECI reports in a file or served by
+ * a local HTTP feed.
+ */
+type Feed struct {
+	// Path to a file or an http(s) URL with one report per line
+	Source string
+	// Only use reports from this station, ex: KLNK
+	Station string
+	// Timezone the day is computed in. Defaults to UTC
+	Location *time.Location
+}
+
+func New(source string) *Feed {
+	return &Feed{
+		Source: source,
+	}
+}
+
+func (f *Feed) Name() string {
+	if f.Station != "" {
+		return "metar/" + f.Station
+	}
+
+	return "metar/" + filepath.Base(f.Source)
+}
+
+// Sources with the same name in different directories are kept apart
+func (f *Feed) CacheKey() string {
+	return "metar/" + f.Source + "/" + f.Station
+}
+
+func (f *Feed) GetLocation(ctx context.Context) (*time.Location, error) {
+	if f.Location == nil {
+		return time.UTC, nil
+	}
+
+	return f.Location, nil
+}
+
+func (f *Feed) GetDailyWeather(ctx context.Context, date time.Time) (*weather.WeatherInfo, error) {
+	tz, _ := f.GetLocation(ctx)
+	day := weather.CalendarDay(date, tz)
+
+	reports, err := f.GetReports(ctx, day.Start)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f.summarize(day, reports)
+}
+
+/**
+ * Reads the source once and summarizes every day from from through to from
+ * it, resolving each report's date against the day being summarized
+ */
+func (f *Feed) GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*weather.WeatherInfo, error) {
+	tz, _ := f.GetLocation(ctx)
+
+	reports, err := f.GetReports(ctx, weather.CalendarDay(from, tz).Start)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return weather.EachDay(ctx, from, to, tz, func(ctx context.Context, date time.Time) (*weather.WeatherInfo, error) {
+		day := weather.CalendarDay(date, tz)
+		resolved := make([]*Report, len(reports))
+
+		for i, report := range reports {
+			resolved[i] = report.At(day.Start)
+		}
+
+		return f.summarize(day, resolved)
+	})
+}
+
+func (f *Feed) summarize(day weather.Day, reports []*Report) (*weather.WeatherInfo, error) {
+	weatherInfo, err := Summarize(day.Start, day.End, reports)
+
+	if err != nil {
+		return nil, err
+	}
+
+	weatherInfo.Provider = f.Name()
+
+	return weatherInfo, nil
+}
+
+// Reads and parses every report from the source
+func (f *Feed) GetReports(ctx context.Context, reference time.Time) ([]*Report, error) {
+	body, err := f.read(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer body.Close()
+
+	reports, err := ParseReports(body, reference)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Station == "" {
+		return reports, nil
+	}
+
+	stationReports := []*Report{}
+
+	for _, report := range reports {
+		if strings.EqualFold(report.Station, f.Station) {
+			stationReports = append(stationReports, report)
+		}
+	}
+
+	return stationReports, nil
+}
+
+func (f *Feed) read(ctx context.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(f.Source, "http://") && !strings.HasPrefix(f.Source, "https://") {
+		return os.Open(f.Source)
+	}
+
+	log.Printf("Making Request to %s", f.Source)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.Source, nil)
+
+	if err != nil {
+		log.Printf("Could not create request %s", err)
+		return nil, err
+	}
+
+	res, err := httpClient.Do(req)
+
+	if err != nil {
+		log.Printf("Error making request: %s", err)
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("metar: request failed with %s", res.Status)
+	}
+
+	return res.Body, nil
+}
+
+/**
+ * Computes the weather for the day starting at start and ending at end.
+ *
+ * The high and low come from the 24 hour max/min group when a report carries
+ * one for the day. Otherwise they are the extremes of the 6 hour max/min
+ * groups covering the day and the individual readings. The average is the
+ * mean of the readings taken during the day.
+ */
+func Summarize(start time.Time, end time.Time, reports []*Report) (*weather.WeatherInfo, error) {
+	observations := []weather.Observation{}
+
+	var high, low, dayHigh, dayLow *float64
+
+	for _, report := range reports {
+		inDay := !report.Time.Before(start) && report.Time.Before(end)
+
+		if inDay && report.Temperature != nil {
+			observations = append(observations, weather.Observation{
+				Time:        report.Time,
+				Temperature: weather.CelciusToFahrenheit(*report.Temperature),
+			})
+
+			high = maxTemp(high, *report.Temperature)
+			low = minTemp(low, *report.Temperature)
+		}
+
+		// 6 hour groups cover the six hours before the report
+		sixHourStart := report.Time.Add(time.Hour * -6)
+		coversDay := !sixHourStart.Before(start) && !report.Time.After(end)
+
+		if coversDay && report.SixHourMax != nil {
+			high = maxTemp(high, *report.SixHourMax)
+		}
+
+		if coversDay && report.SixHourMin != nil {
+			low = minTemp(low, *report.SixHourMin)
+		}
+
+		endOfDay := report.Time.After(end.Add(time.Minute*-10)) && report.Time.Before(end.Add(dayGroupWindow))
+
+		if endOfDay && report.DayMax != nil && report.DayMin != nil {
+			dayHigh = report.DayMax
+			dayLow = report.DayMin
+		}
+	}
+
+	if len(observations) == 0 {
+		return nil, fmt.Errorf("metar: no reports with a temperature between %s and %s", start, end)
+	}
+
+	weatherInfo, err := weather.Summarize(start, observations)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if dayHigh != nil && dayLow != nil {
+		high = dayHigh
+		low = dayLow
+	}
+
+	weatherInfo.High = weather.CelciusToFahrenheit(*high)
+	weatherInfo.Low = weather.CelciusToFahrenheit(*low)
+
+	return weatherInfo, nil
+}
+
+func maxTemp(current *float64, temp float64) *float64 {
+	if current == nil || temp > *current {
+		return &temp
+	}
+
+	return current
+}
+
+func minTemp(current *float64, temp float64) *float64 {
+	if current == nil || temp < *current {
+		return &temp
+	}
+
+	return current
+}
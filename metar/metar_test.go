@@ -0,0 +1,140 @@
+package metar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReport(t *testing.T) {
+	reference := time.Date(2023, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	report, err := ParseReport("METAR KLNK 101854Z 18010KT 10SM CLR 13/M03 A2990 RMK AO2 SLP131 T01281028 10133 20017 58012=", reference)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Station != "KLNK" || !report.Time.Equal(time.Date(2023, 1, 10, 18, 54, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected station/time %s %s", report.Station, report.Time)
+	}
+
+	if *report.Temperature != 12.8 || *report.SixHourMax != 13.3 || *report.SixHourMin != 1.7 {
+		t.Errorf("Unexpected temperatures %v %v %v", *report.Temperature, *report.SixHourMax, *report.SixHourMin)
+	}
+
+	report, err = ParseReport("SPECI KLNK 300554Z 00000KT M05/M08 RMK AO2 401001083", reference)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The 30th is closer to January 11th in December than in January
+	if report.Kind != "SPECI" || report.Time.Month() != time.December || report.Time.Year() != 2022 {
+		t.Errorf("Unexpected kind/time %s %s", report.Kind, report.Time)
+	}
+
+	if *report.Temperature != -5 || *report.DayMax != 10 || *report.DayMin != -8.3 {
+		t.Errorf("Unexpected temperatures %v %v %v", *report.Temperature, *report.DayMax, *report.DayMin)
+	}
+}
+
+const testFeed = `2023/01/10 05:54
+KLNK 100554Z 00000KT 10SM CLR M03/M06 A3010 RMK AO2 T10281061
+KLNK 101154Z 00000KT 10SM CLR M04/M07 A3010 RMK AO2 T10391072 11028
+    21044
+KLNK 101754Z 18010KT 10SM CLR 12/M03 A2990 RMK AO2 T01221028
+KOMA 101754Z 18010KT 10SM CLR 20/M03 A2990 RMK AO2 T02001028
+KLNK 102354Z 18010KT 10SM CLR 06/M03 A2990 RMK AO2 T00611028 10133 20050
+KLNK 110554Z 18010KT 10SM CLR M03/M03 A2990 RMK AO2 T10281028 401391050
+`
+
+func TestFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testFeed))
+	}))
+
+	defer server.Close()
+
+	feed := New(server.URL)
+	feed.Station = "KLNK"
+	feed.Location, _ = time.LoadLocation("America/Chicago")
+
+	info, err := feed.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The 24 hour group is reported at 05:54Z on the 11th, just before
+	// midnight CST
+	if fmt.Sprintf("%.2f/%.2f", info.High, info.Low) != "57.02/23.00" {
+		t.Errorf("Expected the 24 hour group, got %.2f/%.2f", info.High, info.Low)
+	}
+
+	if !strings.HasPrefix(info.Provider, "metar/KLNK") {
+		t.Errorf("Unexpected provider %s", info.Provider)
+	}
+
+	reports, _ := feed.GetReports(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	// Without a 24 hour group the 6 hour groups and readings are used
+	info, err = Summarize(
+		time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 11, 0, 0, 0, 0, time.UTC),
+		reports[:len(reports)-1],
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprintf("%.2f/%.2f", info.High, info.Low) != "55.94/24.08" {
+		t.Errorf("Unexpected high/low %.2f/%.2f", info.High, info.Low)
+	}
+}
+
+func TestFeedRange(t *testing.T) {
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("KLNK METAR FEED\nKLNK NIL=\n" + strings.ReplaceAll(testFeed, "2023/01/10 05:54\n", "") + `
+KLNK 120554Z 18010KT 10SM CLR M01/M03 A2990 RMK AO2 T10111028
+KLNK 311754Z 18010KT 10SM CLR 01/M03 A2990 RMK AO2 T00111028
+`))
+	}))
+
+	defer server.Close()
+
+	feed := New(server.URL)
+	feed.Station = "KLNK"
+
+	weatherInfos, err := feed.GetDailyRange(
+		context.Background(),
+		time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected the feed to be read once, got %d requests", requests)
+	}
+
+	days := []string{}
+
+	for _, info := range weatherInfos {
+		days = append(days, info.Date.Format("Jan 2"))
+	}
+
+	if strings.Join(days, ",") != "Jan 10,Jan 11,Jan 12,Jan 31" {
+		t.Errorf("Unexpected days %v", days)
+	}
+}
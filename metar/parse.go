@@ -0,0 +1,252 @@
+package metar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeGroup        = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	temperatureGroup = regexp.MustCompile(`^(M?\d{2})/(M?\d{2})?$`)
+	tenthsGroup      = regexp.MustCompile(`^T([01]\d{3})([01]\d{3})?$`)
+	sixHourMaxGroup  = regexp.MustCompile(`^1([01]\d{3})$`)
+	sixHourMinGroup  = regexp.MustCompile(`^2([01]\d{3})$`)
+	dayGroup         = regexp.MustCompile(`^4([01]\d{3})([01]\d{3})$`)
+	// Date lines that precede each report in NOAA's tgftp METAR files
+	dateLine = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}$`)
+)
+
+// A decoded METAR or SPECI. Temperatures are celsius and nil when the group
+// was not in the report.
+type Report struct {
+	// METAR or SPECI
+	Kind    string
+	Station string
+	Time    time.Time
+	// Temperature from the remarks T-group when present since it has tenths
+	// precision, otherwise from the main temperature group
+	Temperature *float64
+	// 1snTTT and 2snTTT groups: max and min over the previous six hours
+	SixHourMax *float64
+	SixHourMin *float64
+	// 4snTTTsnTTT group: max and min over the previous 24 hours (since
+	// midnight local standard time)
+	DayMax *float64
+	DayMin *float64
+	Raw    string
+	// The date came from a date line rather than a reference
+	dated bool
+}
+
+/**
+ * Parses a single report. METARs only carry the day of the month so the full
+ * date is resolved as the one closest to reference.
+ */
+func ParseReport(text string, reference time.Time) (*Report, error) {
+	text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "="))
+	groups := strings.Fields(text)
+
+	report := &Report{
+		Kind: "METAR",
+		Raw:  text,
+	}
+
+	if len(groups) > 0 && (groups[0] == "METAR" || groups[0] == "SPECI") {
+		report.Kind = groups[0]
+		groups = groups[1:]
+	}
+
+	if len(groups) < 2 {
+		return nil, fmt.Errorf("metar: report is too short: %q", text)
+	}
+
+	report.Station = groups[0]
+
+	match := timeGroup.FindStringSubmatch(groups[1])
+
+	if match == nil {
+		return nil, fmt.Errorf("metar: invalid time group %q", groups[1])
+	}
+
+	day, _ := strconv.Atoi(match[1])
+	hour, _ := strconv.Atoi(match[2])
+	minute, _ := strconv.Atoi(match[3])
+	report.Time = resolveTime(day, hour, minute, reference)
+
+	remarks := false
+
+	for _, group := range groups[2:] {
+		if group == "RMK" {
+			remarks = true
+			continue
+		}
+
+		if !remarks {
+			if match := temperatureGroup.FindStringSubmatch(group); match != nil && report.Temperature == nil {
+				temp := parseWhole(match[1])
+				report.Temperature = &temp
+			}
+
+			continue
+		}
+
+		if match := tenthsGroup.FindStringSubmatch(group); match != nil {
+			temp := parseTenths(match[1])
+			report.Temperature = &temp
+		} else if match := sixHourMaxGroup.FindStringSubmatch(group); match != nil {
+			temp := parseTenths(match[1])
+			report.SixHourMax = &temp
+		} else if match := sixHourMinGroup.FindStringSubmatch(group); match != nil {
+			temp := parseTenths(match[1])
+			report.SixHourMin = &temp
+		} else if match := dayGroup.FindStringSubmatch(group); match != nil {
+			dayMax := parseTenths(match[1])
+			dayMin := parseTenths(match[2])
+			report.DayMax = &dayMax
+			report.DayMin = &dayMin
+		}
+	}
+
+	return report, nil
+}
+
+/**
+ * Parses one report per line. Reports may wrap onto indented continuation
+ * lines and may be preceded by a YYYY/MM/DD HH:MM line as in NOAA's files,
+ * which is used as the reference date for the report that follows. Lines
+ * that are not reports, like headers or corrupted reports, are logged and
+ * skipped; it only fails when no line is a report.
+ */
+func ParseReports(r io.Reader, reference time.Time) ([]*Report, error) {
+	reports := []*Report{}
+	scanner := bufio.NewScanner(r)
+	lines := []string{}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if len(lines) > 0 && strings.TrimSpace(line) != "" && (line[0] == ' ' || line[0] == '\t') {
+			lines[len(lines)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	lineReference := reference
+	dated := false
+
+	for _, line := range lines {
+		if dateLine.MatchString(line) {
+			if t, err := time.Parse("2006/01/02 15:04", line); err == nil {
+				lineReference = t
+				dated = true
+			}
+
+			continue
+		}
+
+		report, err := ParseReport(line, lineReference)
+
+		if err != nil {
+			log.Printf("Skipping line: %s", err)
+			lineReference, dated = reference, false
+			continue
+		}
+
+		report.dated = dated
+		reports = append(reports, report)
+		lineReference, dated = reference, false
+	}
+
+	if len(reports) == 0 {
+		return nil, errors.New("metar: no reports found")
+	}
+
+	return reports, nil
+}
+
+/**
+ * The report with its date resolved against reference instead. Reports dated
+ * by a date line are returned as they are.
+ */
+func (r *Report) At(reference time.Time) *Report {
+	if r.dated {
+		return r
+	}
+
+	report := *r
+	report.Time = resolveTime(r.Time.Day(), r.Time.Hour(), r.Time.Minute(), reference)
+
+	return &report
+}
+
+// The UTC time with the given day of month that is closest to reference
+func resolveTime(day int, hour int, minute int, reference time.Time) time.Time {
+	reference = reference.UTC()
+	best := time.Time{}
+
+	for _, months := range []int{-1, 0, 1} {
+		month := time.Date(reference.Year(), reference.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+		candidate := time.Date(month.Year(), month.Month(), day, hour, minute, 0, 0, time.UTC)
+
+		// Day 31 in a 30 day month rolls over and is not a real candidate
+		if candidate.Month() != month.Month() {
+			continue
+		}
+
+		if best.IsZero() || absDuration(candidate.Sub(reference)) < absDuration(best.Sub(reference)) {
+			best = candidate
+		}
+	}
+
+	return best
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
+
+// M05 -> -5
+func parseWhole(value string) float64 {
+	sign := 1.0
+
+	if strings.HasPrefix(value, "M") {
+		sign = -1
+		value = value[1:]
+	}
+
+	whole, _ := strconv.Atoi(value)
+
+	return sign * float64(whole)
+}
+
+// 1083 -> -8.3
+func parseTenths(value string) float64 {
+	sign := 1.0
+
+	if value[0] == '1' {
+		sign = -1
+	}
+
+	tenths, _ := strconv.Atoi(value[1:])
+
+	return sign * float64(tenths) / 10
+}
@@ -0,0 +1,396 @@
+package noaa
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+const dateFormat = "2006-01-02"
+
+// Official daily values for a single day, in fahrenheit. Nil when the element
+// was missing or failed NOAA's quality checks.
+type DailyRecord struct {
+	Station string
+	Date    string
+	TMax    *float64
+	TMin    *float64
+	TAvg    *float64
+}
+
+/**
+ * Reads official daily temperatures from a NOAA GHCN-Daily .dly file or a
+ * Climate Data Online (CDO) CSV export.
+ * @see https://www.ncei.noaa.gov/pub/data/ghcn/daily/readme.txt
+ */
+type File struct {
+	Path string
+	// CDO CSV exports can be in standard (fahrenheit) or metric (celsius)
+	// units. GHCN-Daily files are always tenths of a degree celsius.
+	Metric bool
+	// Only use rows for this station id, ex: USW00014939. CDO exports can
+	// contain more than one station.
+	StationId string
+	// Timezone used to decide which day is "yesterday". Defaults to UTC
+	Location *time.Location
+
+	once    sync.Once
+	err     error
+	records map[string]*DailyRecord
+}
+
+func New(path string) *File {
+	return &File{
+		Path: path,
+	}
+}
+
+func (f *File) Name() string {
+	return "noaa/" + filepath.Base(f.Path)
+}
+
+// Files with the same name in different directories are kept apart
+func (f *File) CacheKey() string {
+	path, err := filepath.Abs(f.Path)
+
+	if err != nil {
+		path = f.Path
+	}
+
+	return fmt.Sprintf("noaa/%s/%s/metric=%t", path, f.StationId, f.Metric)
+}
+
+func (f *File) GetLocation(ctx context.Context) (*time.Location, error) {
+	if f.Location == nil {
+		return time.UTC, nil
+	}
+
+	return f.Location, nil
+}
+
+func (f *File) GetDailyWeather(ctx context.Context, date time.Time) (*weather.WeatherInfo, error) {
+	tz, _ := f.GetLocation(ctx)
+
+	return f.GetWeatherInfo(weather.CalendarDay(date, tz).Start)
+}
+
+/**
+ * Returns the official values for a single day. The average is TAVG when the
+ * station reports it and (TMAX+TMIN)/2 otherwise.
+ */
+func (f *File) GetWeatherInfo(date time.Time) (*weather.WeatherInfo, error) {
+	records, err := f.Records()
+
+	if err != nil {
+		return nil, err
+	}
+
+	record, ok := records[date.Format(dateFormat)]
+
+	if !ok || record.TMax == nil || record.TMin == nil {
+		return nil, fmt.Errorf("noaa: no TMAX/TMIN for %s in %s", date.Format(dateFormat), f.Path)
+	}
+
+	avg := (*record.TMax + *record.TMin) / 2
+
+	if record.TAvg != nil {
+		avg = *record.TAvg
+	}
+
+	return &weather.WeatherInfo{
+		Date:     date,
+		High:     *record.TMax,
+		Low:      *record.TMin,
+		Average:  avg,
+		Provider: f.Name(),
+	}, nil
+}
+
+/**
+ * Returns the official values for every day from from through to that the
+ * file has a TMAX and TMIN for
+ */
+func (f *File) GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*weather.WeatherInfo, error) {
+	if _, err := f.Records(); err != nil {
+		return nil, err
+	}
+
+	tz, _ := f.GetLocation(ctx)
+	weatherInfos := []*weather.WeatherInfo{}
+
+	for _, day := range weather.Days(from, to, tz) {
+		weatherInfo, err := f.GetWeatherInfo(day)
+
+		if err != nil {
+			log.Printf("Skipping day: %s", err)
+			continue
+		}
+
+		weatherInfos = append(weatherInfos, weatherInfo)
+	}
+
+	if len(weatherInfos) == 0 {
+		return nil, weather.ErrNoDays
+	}
+
+	return weatherInfos, nil
+}
+
+// Every day in the file keyed by YYYY-MM-DD. The file is only read once.
+func (f *File) Records() (map[string]*DailyRecord, error) {
+	f.once.Do(func() {
+		f.records, f.err = f.load()
+
+		if f.err == nil {
+			log.Printf("Loaded %d days from %s", len(f.records), f.Path)
+		}
+	})
+
+	return f.records, f.err
+}
+
+func (f *File) load() (map[string]*DailyRecord, error) {
+	file, err := os.Open(f.Path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(f.Path), ".csv") {
+		return ParseCDO(file, f.Metric, f.StationId)
+	}
+
+	return ParseGHCNDaily(file, f.StationId)
+}
+
+/**
+ * Parses the fixed width GHCN-Daily format. Each line is one element for one
+ * month of a station:
+ *
+ *   ID (11) YEAR (4) MONTH (2) ELEMENT (4) then 31 x [VALUE (5) MFLAG QFLAG SFLAG]
+ *
+ * Values are tenths of a degree celsius and -9999 when missing. Values with a
+ * quality flag failed one of NOAA's checks and are skipped.
+ */
+func ParseGHCNDaily(r io.Reader, stationId string) (map[string]*DailyRecord, error) {
+	records := map[string]*DailyRecord{}
+	stations := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if len(line) < 21 {
+			return nil, fmt.Errorf("noaa: line %d is too short", lineNumber)
+		}
+
+		station := line[0:11]
+		element := line[17:21]
+
+		if stationId != "" && station != stationId {
+			continue
+		}
+
+		if element != "TMAX" && element != "TMIN" && element != "TAVG" {
+			continue
+		}
+
+		stations[station] = true
+
+		year, err := strconv.Atoi(line[11:15])
+
+		if err != nil {
+			return nil, fmt.Errorf("noaa: line %d has an invalid year: %w", lineNumber, err)
+		}
+
+		month, err := strconv.Atoi(line[15:17])
+
+		if err != nil {
+			return nil, fmt.Errorf("noaa: line %d has an invalid month: %w", lineNumber, err)
+		}
+
+		for day := 1; day <= 31; day++ {
+			offset := 21 + (day-1)*8
+
+			if offset+8 > len(line) {
+				break
+			}
+
+			value, err := strconv.Atoi(strings.TrimSpace(line[offset : offset+5]))
+
+			if err != nil {
+				return nil, fmt.Errorf("noaa: line %d has an invalid value for day %d: %w", lineNumber, day, err)
+			}
+
+			qualityFlag := line[offset+6]
+
+			if value == -9999 || qualityFlag != ' ' {
+				continue
+			}
+
+			date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+
+			// Days past the end of the month are always missing but guard
+			// against them rolling over into the next month
+			if date.Day() != day {
+				continue
+			}
+
+			temp := weather.CelciusToFahrenheit(float64(value) / 10)
+			setElement(records, station, date.Format(dateFormat), element, temp)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := singleStation(stations); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+/**
+ * Parses a Climate Data Online daily summaries CSV export. Only the STATION,
+ * DATE, TMAX, TMIN and TAVG columns are used; empty cells are missing values.
+ */
+func ParseCDO(r io.Reader, metric bool, stationId string) (map[string]*DailyRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+
+	if _, ok := columns["DATE"]; !ok {
+		return nil, errors.New("noaa: CSV is missing a DATE column")
+	}
+
+	records := map[string]*DailyRecord{}
+	stations := map[string]bool{}
+
+	for {
+		row, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		station := column(row, columns, "STATION")
+
+		if stationId != "" && station != stationId {
+			continue
+		}
+
+		date := column(row, columns, "DATE")
+		stations[station] = true
+
+		for _, element := range []string{"TMAX", "TMIN", "TAVG"} {
+			value := column(row, columns, element)
+
+			if value == "" {
+				continue
+			}
+
+			temp, err := strconv.ParseFloat(value, 64)
+
+			if err != nil {
+				return nil, fmt.Errorf("noaa: invalid %s %q on %s: %w", element, value, date, err)
+			}
+
+			if metric {
+				temp = weather.CelciusToFahrenheit(temp)
+			}
+
+			setElement(records, station, date, element, temp)
+		}
+	}
+
+	if err := singleStation(stations); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// Records are kept per day, so days from more than one station would be mixed together
+func singleStation(stations map[string]bool) error {
+	if len(stations) <= 1 {
+		return nil
+	}
+
+	ids := []string{}
+
+	for id := range stations {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return fmt.Errorf("noaa: file has more than one station (%s), set the station id to use", strings.Join(ids, ", "))
+}
+
+func column(row []string, columns map[string]int, name string) string {
+	i, ok := columns[name]
+
+	if !ok || i >= len(row) {
+		return ""
+	}
+
+	return strings.TrimSpace(row[i])
+}
+
+func setElement(records map[string]*DailyRecord, station string, date string, element string, temp float64) {
+	record, ok := records[date]
+
+	if !ok {
+		record = &DailyRecord{
+			Station: station,
+			Date:    date,
+		}
+
+		records[date] = record
+	}
+
+	switch element {
+	case "TMAX":
+		record.TMax = &temp
+	case "TMIN":
+		record.TMin = &temp
+	case "TAVG":
+		record.TAvg = &temp
+	}
+}
@@ -0,0 +1,123 @@
+package noaa
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Builds a GHCN-Daily line with the given values for the first days of the month
+func dlyLine(element string, values ...string) string {
+	line := "USW00014939202301" + element
+
+	for day := 0; day < 31; day++ {
+		value := "-9999"
+		flags := "   "
+
+		if day < len(values) {
+			parts := strings.SplitN(values[day], "|", 2)
+			value = parts[0]
+
+			if len(parts) == 2 {
+				flags = parts[1]
+			}
+		}
+
+		line += fmt.Sprintf("%5s%s", value, flags)
+	}
+
+	return line
+}
+
+func writeFile(t *testing.T, name string, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestGHCNDaily(t *testing.T) {
+	contents := strings.Join([]string{
+		dlyLine("TMAX", "100", "128", "500| X "),
+		dlyLine("TMIN", "-50", "-44", "-100"),
+		dlyLine("PRCP", "0", "0", "0"),
+	}, "\n")
+
+	file := New(writeFile(t, "USW00014939.dly", contents))
+
+	info, err := file.GetDailyWeather(context.Background(), time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 12.8C and -4.4C
+	if fmt.Sprintf("%.2f/%.2f/%.2f", info.High, info.Low, info.Average) != "55.04/24.08/39.56" {
+		t.Errorf("Unexpected weather %+v", info)
+	}
+
+	if info.Provider != "noaa/USW00014939.dly" {
+		t.Errorf("Unexpected provider %s", info.Provider)
+	}
+
+	// The third's TMAX failed a quality check
+	_, err = file.GetWeatherInfo(time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC))
+
+	if err == nil {
+		t.Errorf("Expected an error for a day without a valid TMAX")
+	}
+}
+
+func TestCDO(t *testing.T) {
+	contents := `"STATION","NAME","DATE","TAVG","TMAX","TMIN"
+"USW00014939","LINCOLN AIRPORT, NE US","2023-01-09","","40","20"
+"USW00014939","LINCOLN AIRPORT, NE US","2023-01-10","37","55","25"
+"USW00094918","OMAHA EPPLEY AIRFIELD, NE US","2023-01-10","30","50","10"
+`
+
+	file := New(writeFile(t, "export.csv", contents))
+	file.StationId = "USW00014939"
+
+	info, err := file.GetWeatherInfo(time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.High != 55 || info.Low != 25 || info.Average != 37 {
+		t.Errorf("Unexpected weather %+v", info)
+	}
+
+	// No TAVG so the average falls back to (TMAX+TMIN)/2
+	info, err = file.GetWeatherInfo(time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Average != 30 {
+		t.Errorf("Expected an average of 30, got %f", info.Average)
+	}
+}
+
+func TestCDOMoreThanOneStation(t *testing.T) {
+	contents := `"STATION","DATE","TMAX","TMIN"
+"A","2023-01-10","50","20"
+"B","2023-01-10","90",""
+`
+
+	file := New(writeFile(t, "export.csv", contents))
+
+	_, err := file.GetWeatherInfo(time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err == nil || !strings.Contains(err.Error(), "more than one station (A, B)") {
+		t.Errorf("Expected stations not to be mixed, got %v", err)
+	}
+}
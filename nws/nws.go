@@ -0,0 +1,194 @@
+package nws
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+// Used when the source is a URL so a hung connection can't stall a run
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+/**
+ * Reads the official high, low and average from NWS Daily Climate Reports
+ * (CLI), the numbers the local news reports, in a file or served by a local
+ * HTTP endpoint.
+ */
+type Climate struct {
+	// Path to a file or an http(s) URL with one or more CLI products
+	Source string
+	// Timezone of the office issuing the reports, used to decide which day
+	// is "yesterday". Defaults to UTC
+	Location *time.Location
+}
+
+func New(source string) *Climate {
+	return &Climate{
+		Source: source,
+	}
+}
+
+func (c *Climate) Name() string {
+	return "nws/" + filepath.Base(c.Source)
+}
+
+// Sources with the same name in different directories are kept apart
+func (c *Climate) CacheKey() string {
+	return "nws/" + c.Source
+}
+
+func (c *Climate) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+
+	return c.Location
+}
+
+func (c *Climate) GetLocation(ctx context.Context) (*time.Location, error) {
+	return c.location(), nil
+}
+
+func (c *Climate) GetDailyWeather(ctx context.Context, date time.Time) (*weather.WeatherInfo, error) {
+	reports, err := c.GetReports(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.weatherInfo(weather.CalendarDay(date, c.location()).Start, reports)
+}
+
+/**
+ * Returns the official values for every day from from through to that has a
+ * final report. The source is only read once.
+ */
+func (c *Climate) GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*weather.WeatherInfo, error) {
+	reports, err := c.GetReports(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	weatherInfos := []*weather.WeatherInfo{}
+
+	for _, day := range weather.Days(from, to, c.location()) {
+		weatherInfo, err := c.weatherInfo(day, reports)
+
+		if err != nil {
+			log.Printf("%s", err)
+			continue
+		}
+
+		weatherInfos = append(weatherInfos, weatherInfo)
+	}
+
+	if len(weatherInfos) == 0 {
+		return nil, weather.ErrNoDays
+	}
+
+	return weatherInfos, nil
+}
+
+// Reads and parses every report from the source
+func (c *Climate) GetReports(ctx context.Context) ([]*Report, error) {
+	body, err := c.read(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer body.Close()
+
+	return ParseReports(body, c.location())
+}
+
+/**
+ * Uses the last final report for the day. Reports issued during the day are
+ * only used to explain why there is no final one yet.
+ */
+func (c *Climate) weatherInfo(date time.Time, reports []*Report) (*weather.WeatherInfo, error) {
+	var report *Report
+	partial := false
+
+	for _, r := range reports {
+		if r.Date.Format("2006-01-02") != date.Format("2006-01-02") {
+			continue
+		}
+
+		if !r.Final {
+			partial = true
+			continue
+		}
+
+		report = r
+	}
+
+	if report == nil && partial {
+		return nil, fmt.Errorf("nws: only partial climate reports for %s", date.Format("Jan 2 2006"))
+	}
+
+	if report == nil {
+		return nil, fmt.Errorf("nws: no climate report for %s", date.Format("Jan 2 2006"))
+	}
+
+	if report.Maximum == nil || report.Minimum == nil {
+		return nil, fmt.Errorf("nws: the climate report for %s is missing the maximum or minimum", date.Format("Jan 2 2006"))
+	}
+
+	average := (*report.Maximum + *report.Minimum) / 2
+
+	if report.Average != nil {
+		average = *report.Average
+	}
+
+	return &weather.WeatherInfo{
+		Date:    date,
+		High:    *report.Maximum,
+		Low:     *report.Minimum,
+		Average: average,
+		Departure: &weather.Departure{
+			High:    report.MaximumDeparture,
+			Low:     report.MinimumDeparture,
+			Average: report.AverageDeparture,
+		},
+		Provider: c.Name(),
+	}, nil
+}
+
+func (c *Climate) read(ctx context.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(c.Source, "http://") && !strings.HasPrefix(c.Source, "https://") {
+		return os.Open(c.Source)
+	}
+
+	log.Printf("Making Request to %s", c.Source)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Source, nil)
+
+	if err != nil {
+		log.Printf("Could not create request %s", err)
+		return nil, err
+	}
+
+	res, err := httpClient.Do(req)
+
+	if err != nil {
+		log.Printf("Error making request: %s", err)
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("nws: request failed with %s", res.Status)
+	}
+
+	return res.Body, nil
+}
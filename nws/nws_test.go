@@ -0,0 +1,132 @@
+package nws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testReports = `000
+CDUS43 KOAX 102201
+CLILNK
+
+CLIMATE REPORT
+NATIONAL WEATHER SERVICE OMAHA/VALLEY NE
+401 PM CST TUE JAN 10 2023
+
+...THE LINCOLN NE CLIMATE SUMMARY FOR JANUARY 10 2023...
+VALID TODAY AS OF 0400 PM LOCAL TIME.
+
+WEATHER ITEM   OBSERVED TIME   RECORD YEAR NORMAL DEPARTURE LAST
+                VALUE   (LST)  VALUE       VALUE  FROM      YEAR
+                                                  NORMAL
+...................................................................
+TEMPERATURE (F)
+ TODAY
+  MAXIMUM         54    245 PM  64    2012  34     20       23
+  MINIMUM         23    711 AM -19    1886  13     10       11
+  AVERAGE         39                        24     15       17
+
+000
+CDUS43 KOAX 110731
+CLILNK
+
+CLIMATE REPORT
+NATIONAL WEATHER SERVICE OMAHA/VALLEY NE
+131 AM CST WED JAN 11 2023
+
+...THE LINCOLN NE CLIMATE SUMMARY FOR JANUARY 10 2023...
+VALID AS OF 1200 AM LOCAL TIME.
+
+WEATHER ITEM   OBSERVED TIME   RECORD YEAR NORMAL DEPARTURE LAST
+                VALUE   (LST)  VALUE       VALUE  FROM      YEAR
+                                                  NORMAL
+...................................................................
+TEMPERATURE (F)
+ YESTERDAY
+  MAXIMUM         65R   245 PM  64    2012  34     31       23
+  MINIMUM         23    MM     -19    1886  13     10       11
+  AVERAGE         44                        24     20       17
+ MONTH TO DATE
+  MAXIMUM         65                              
+
+PRECIPITATION (IN)
+  YESTERDAY        0.00          0.39 1975   0.02  -0.02     0.00
+
+...THE LINCOLN NE CLIMATE SUMMARY FOR JANUARY 11 2023...
+VALID TODAY AS OF 0400 PM LOCAL TIME.
+
+TEMPERATURE (F)
+ TODAY
+  MAXIMUM         40    145 PM  60    1990  34      6       30
+  MINIMUM         MM
+`
+
+func TestParseReports(t *testing.T) {
+	reports, err := ParseReports(strings.NewReader(testReports), time.UTC)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reports) != 3 {
+		t.Fatalf("Expected 3 reports, got %d", len(reports))
+	}
+
+	report := reports[1]
+
+	if !report.Final || reports[0].Final || reports[2].Final {
+		t.Errorf("Expected only the report issued after midnight to be final")
+	}
+
+	got := fmt.Sprintf(
+		"%s %v/%v/%v %v/%v/%v",
+		report.Date.Format("2006-01-02"),
+		*report.Maximum,
+		*report.Minimum,
+		*report.Average,
+		*report.MaximumDeparture,
+		*report.MinimumDeparture,
+		*report.AverageDeparture,
+	)
+
+	if got != "2023-01-10 65/23/44 31/10/20" {
+		t.Errorf("Unexpected report %s", got)
+	}
+
+	if reports[2].Minimum != nil {
+		t.Errorf("Expected a missing minimum to be nil")
+	}
+}
+
+func TestGetDailyWeather(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cli.txt")
+
+	if err := os.WriteFile(path, []byte(testReports), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	climate := New(path)
+	climate.Location, _ = time.LoadLocation("America/Chicago")
+
+	info, err := climate.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.High != 65 || info.Low != 23 || info.Average != 44 || *info.Departure.Average != 20 {
+		t.Errorf("Expected the final report's values, got %+v", info)
+	}
+
+	// There is only a partial report for the 11th
+	_, err = climate.GetDailyWeather(context.Background(), time.Date(2023, 1, 11, 0, 0, 0, 0, time.UTC))
+
+	if err == nil || !strings.Contains(err.Error(), "partial") {
+		t.Errorf("Expected a partial report to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package nws
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ...THE LINCOLN NE CLIMATE SUMMARY FOR JANUARY 10 2023...
+var summaryPattern = regexp.MustCompile(`CLIMATE SUMMARY FOR ([A-Z]+ \d{1,2} \d{4})`)
+
+// A day's temperatures from a Daily Climate Report (CLI), in fahrenheit. Values
+// the report lists as missing (MM) are nil.
+type Report struct {
+	// Local calendar day the report summarizes
+	Date time.Time
+	// Reports issued during the day ("VALID TODAY AS OF 0500 PM") only cover
+	// the day so far
+	Final bool
+
+	Maximum *float64
+	Minimum *float64
+	Average *float64
+
+	// Degrees above the 30 year normal, negative when below
+	MaximumDeparture *float64
+	MinimumDeparture *float64
+	AverageDeparture *float64
+}
+
+/**
+ * Parses every Daily Climate Report in r. Dates are read in tz, the timezone
+ * of the office issuing the reports.
+ * @see https://www.weather.gov/media/directives/010_pdfs/pd01011005curr.pdf
+ */
+func ParseReports(r io.Reader, tz *time.Location) ([]*Report, error) {
+	reports := []*Report{}
+	scanner := bufio.NewScanner(r)
+
+	var report *Report
+	inTemperature := false
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if match := summaryPattern.FindStringSubmatch(strings.ToUpper(line)); match != nil {
+			date, err := time.ParseInLocation("January 2 2006", match[1], tz)
+
+			if err != nil {
+				return nil, err
+			}
+
+			report = &Report{Date: date, Final: true}
+			reports = append(reports, report)
+			inTemperature = false
+
+			continue
+		}
+
+		if report == nil {
+			continue
+		}
+
+		if strings.Contains(line, "VALID TODAY AS OF") {
+			report.Final = false
+		}
+
+		if line == "TEMPERATURE (F)" {
+			inTemperature = true
+			continue
+		}
+
+		if !inTemperature {
+			continue
+		}
+
+		// The section ends at the first blank line
+		if line == "" {
+			inTemperature = false
+			continue
+		}
+
+		parseTemperatureLine(report, strings.Fields(line))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+/**
+ * Reads a row of the temperature table. Maximum and minimum rows have the
+ * value, the time it happened, the record and its year, the normal, the
+ * departure from normal and last year's value. Average rows leave out the
+ * time and record.
+ *
+ *   MAXIMUM         55    245 PM  64    2012  34     21       23
+ *   AVERAGE         39                        24     15       17
+ */
+func parseTemperatureLine(report *Report, fields []string) {
+	if len(fields) < 2 {
+		return
+	}
+
+	label := fields[0]
+
+	switch label {
+	case "MAXIMUM", "MINIMUM":
+		// Only the first row of each is for the day
+		if label == "MAXIMUM" && report.Maximum != nil || label == "MINIMUM" && report.Minimum != nil {
+			return
+		}
+
+		columns := withoutTime(fields)
+		value := parseValue(columns[1])
+		var departure *float64
+
+		if len(columns) > 5 {
+			departure = parseValue(columns[5])
+		}
+
+		if label == "MAXIMUM" {
+			report.Maximum, report.MaximumDeparture = value, departure
+		} else {
+			report.Minimum, report.MinimumDeparture = value, departure
+		}
+	case "AVERAGE":
+		if report.Average != nil {
+			return
+		}
+
+		report.Average = parseValue(fields[1])
+
+		if len(fields) > 3 {
+			report.AverageDeparture = parseValue(fields[3])
+		}
+	}
+}
+
+// Removes the time column, "245 PM" or "MM" when missing
+func withoutTime(fields []string) []string {
+	if len(fields) > 3 && (fields[3] == "AM" || fields[3] == "PM") {
+		return append(append([]string{}, fields[:2]...), fields[4:]...)
+	}
+
+	// Without an AM/PM the row only has an extra column when the time is
+	// missing
+	if len(fields) > 7 && fields[2] == "MM" {
+		return append(append([]string{}, fields[:2]...), fields[3:]...)
+	}
+
+	return fields
+}
+
+// Parses a value, nil when missing (MM). New records are marked with an R
+func parseValue(field string) *float64 {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(field, "R"), 64)
+
+	if err != nil {
+		return nil
+	}
+
+	return &value
+}
@@ -0,0 +1,284 @@
+package openmeteo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+var ARCHIVE_API_URL *url.URL
+
+const dateFormat = "2006-01-02"
+
+// Used for every Open-Meteo request so a hung connection can't stall a run
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Open-Meteo historical weather. No token is required.
+type Archive struct {
+	// Defaults to ARCHIVE_API_URL
+	BaseUrl   *url.URL
+	Latitude  float64
+	Longitude float64
+	// Timezone the day is computed in. When nil Open-Meteo picks the
+	// timezone of the coordinates.
+	Location *time.Location
+	// The timezone Open-Meteo picked, once it has been looked up
+	autoLocation *time.Location
+}
+
+type ArchiveResponse struct {
+	Latitude  float64       `json:"latitude"`
+	Longitude float64       `json:"longitude"`
+	Timezone  string        `json:"timezone"`
+	Daily     *DailyWeather `json:"daily"`
+	// Set along with a 400 status when the request is invalid
+	Error  bool   `json:"error"`
+	Reason string `json:"reason"`
+}
+
+// Values are nil for days Open-Meteo does not have data for yet
+type DailyWeather struct {
+	Time              []string   `json:"time"`
+	Temperature2mMax  []*float64 `json:"temperature_2m_max"`
+	Temperature2mMin  []*float64 `json:"temperature_2m_min"`
+	Temperature2mMean []*float64 `json:"temperature_2m_mean"`
+}
+
+func New(latitude float64, longitude float64) *Archive {
+	return &Archive{
+		BaseUrl:   ARCHIVE_API_URL,
+		Latitude:  latitude,
+		Longitude: longitude,
+	}
+}
+
+func (a *Archive) Name() string {
+	return "open-meteo"
+}
+
+func (a *Archive) CacheKey() string {
+	return fmt.Sprintf("open-meteo/%.4f,%.4f", a.Latitude, a.Longitude)
+}
+
+/**
+ * Returns the configured timezone. When there isn't one the timezone
+ * Open-Meteo picks for the coordinates is looked up with a small request and
+ * remembered for subsequent calls, so yesterday is the coordinates' yesterday.
+ */
+func (a *Archive) GetLocation(ctx context.Context) (*time.Location, error) {
+	if a.Location != nil {
+		return a.Location, nil
+	}
+
+	if a.autoLocation != nil {
+		return a.autoLocation, nil
+	}
+
+	// Recent days can be missing from the archive, but it still answers
+	day := time.Now().AddDate(0, 0, -7)
+	archive, err := a.GetDailyData(ctx, day, day)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tz, err := a.location(archive)
+
+	if err != nil {
+		return nil, err
+	}
+
+	a.autoLocation = tz
+
+	return tz, nil
+}
+
+func (a *Archive) GetDailyWeather(ctx context.Context, day time.Time) (*weather.WeatherInfo, error) {
+	archive, err := a.GetDailyData(ctx, day, day)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tz, err := a.location(archive)
+
+	if err != nil {
+		return nil, err
+	}
+
+	date := day.Format(dateFormat)
+
+	for i, dailyDate := range archive.Daily.Time {
+		if dailyDate == date {
+			return a.weatherInfo(archive.Daily, i, tz)
+		}
+	}
+
+	return nil, fmt.Errorf("openmeteo: %s not in response", date)
+}
+
+/**
+ * Returns one summary per day from from through to using a single request.
+ * Days Open-Meteo does not have data for yet are left out.
+ */
+func (a *Archive) GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*weather.WeatherInfo, error) {
+	archive, err := a.GetDailyData(ctx, from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tz, err := a.location(archive)
+
+	if err != nil {
+		return nil, err
+	}
+
+	weatherInfos := []*weather.WeatherInfo{}
+
+	for i := range archive.Daily.Time {
+		weatherInfo, err := a.weatherInfo(archive.Daily, i, tz)
+
+		if err != nil {
+			log.Printf("Skipping day: %s", err)
+			continue
+		}
+
+		weatherInfos = append(weatherInfos, weatherInfo)
+	}
+
+	if len(weatherInfos) == 0 {
+		return nil, weather.ErrNoDays
+	}
+
+	return weatherInfos, nil
+}
+
+// The configured timezone or the one Open-Meteo picked for the response
+func (a *Archive) location(archive *ArchiveResponse) (*time.Location, error) {
+	if a.Location != nil {
+		return a.Location, nil
+	}
+
+	tz, err := time.LoadLocation(archive.Timezone)
+
+	if err != nil {
+		log.Printf("Cannot load timezone %s", err)
+		return nil, err
+	}
+
+	return tz, nil
+}
+
+func (a *Archive) weatherInfo(daily *DailyWeather, i int, tz *time.Location) (*weather.WeatherInfo, error) {
+	date := daily.Time[i]
+
+	if i >= len(daily.Temperature2mMax) || i >= len(daily.Temperature2mMin) || i >= len(daily.Temperature2mMean) {
+		return nil, fmt.Errorf("openmeteo: incomplete data for %s", date)
+	}
+
+	high := daily.Temperature2mMax[i]
+	low := daily.Temperature2mMin[i]
+	avg := daily.Temperature2mMean[i]
+
+	if high == nil || low == nil || avg == nil {
+		return nil, fmt.Errorf("openmeteo: no data for %s yet", date)
+	}
+
+	start, err := time.ParseInLocation(dateFormat, date, tz)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &weather.WeatherInfo{
+		Date:     start,
+		High:     *high,
+		Low:      *low,
+		Average:  *avg,
+		Provider: a.Name(),
+	}, nil
+}
+
+/**
+ * Requests daily max/min/mean temperatures in fahrenheit for a range of dates
+ * @see https://open-meteo.com/en/docs/historical-weather-api
+ */
+func (a *Archive) GetDailyData(ctx context.Context, start time.Time, end time.Time) (*ArchiveResponse, error) {
+	url := *a.BaseUrl
+	query := url.Query()
+
+	query.Add("latitude", fmt.Sprintf("%f", a.Latitude))
+	query.Add("longitude", fmt.Sprintf("%f", a.Longitude))
+	query.Add("start_date", start.Format(dateFormat))
+	query.Add("end_date", end.Format(dateFormat))
+	query.Add("daily", "temperature_2m_max,temperature_2m_min,temperature_2m_mean")
+	query.Add("temperature_unit", "fahrenheit")
+
+	if a.Location != nil {
+		query.Add("timezone", a.Location.String())
+	} else {
+		query.Add("timezone", "auto")
+	}
+
+	url.RawQuery = query.Encode()
+
+	log.Printf("Making Request to %s", url.String())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+
+	if err != nil {
+		log.Printf("Could not create request %s", err)
+		return nil, err
+	}
+
+	res, err := httpClient.Do(req)
+
+	if err != nil {
+		log.Printf("Error making request: %s", err)
+		return nil, err
+	}
+
+	defer res.Body.Close()
+	resBody, err := ioutil.ReadAll(res.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var archive ArchiveResponse
+	err = json.Unmarshal(resBody, &archive)
+
+	if err != nil {
+		log.Printf("Could not parse response body %s", err)
+		return nil, err
+	}
+
+	if archive.Error || res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("openmeteo: request failed with %s: %s", res.Status, archive.Reason)
+	}
+
+	if archive.Daily == nil {
+		return nil, fmt.Errorf("openmeteo: no daily data in response")
+	}
+
+	return &archive, nil
+}
+
+func init() {
+	archiveUrl, err := url.Parse("https://archive-api.open-meteo.com/v1/archive")
+
+	if err != nil {
+		log.Fatalf("Cannot parse url %v", err)
+		return
+	}
+
+	ARCHIVE_API_URL = archiveUrl
+}
@@ -0,0 +1,97 @@
+package openmeteo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const testArchive = `
+{
+    "latitude": 40.8,
+    "longitude": -96.7,
+    "timezone": "America/Chicago",
+    "daily_units": {"time": "iso8601", "temperature_2m_max": "°F"},
+    "daily": {
+        "time": ["2023-01-09", "2023-01-10", "2023-01-11"],
+        "temperature_2m_max": [40.1, 55.4, null],
+        "temperature_2m_min": [20.3, 24.8, null],
+        "temperature_2m_mean": [30.0, 35.2, null]
+    }
+}
+`
+
+func newTestArchive(t *testing.T, status int, body string) *Archive {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		if query.Get("latitude") != "40.800000" || query.Get("timezone") != "auto" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+
+	t.Cleanup(server.Close)
+
+	archive := New(40.8, -96.7)
+	archive.BaseUrl, _ = url.Parse(server.URL)
+
+	return archive
+}
+
+func TestArchive(t *testing.T) {
+	archive := newTestArchive(t, http.StatusOK, testArchive)
+
+	info, err := archive.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Date.Format(time.RFC3339) != "2023-01-10T00:00:00-06:00" {
+		t.Errorf("Unexpected date %s", info.Date)
+	}
+
+	if info.High != 55.4 || info.Low != 24.8 || info.Average != 35.2 || info.Provider != "open-meteo" {
+		t.Errorf("Unexpected weather %+v", info)
+	}
+}
+
+func TestArchiveMissingData(t *testing.T) {
+	archive := newTestArchive(t, http.StatusOK, testArchive)
+
+	_, err := archive.GetDailyWeather(context.Background(), time.Date(2023, 1, 11, 0, 0, 0, 0, time.UTC))
+
+	if err == nil {
+		t.Errorf("Expected an error for a day without data")
+	}
+}
+
+func TestArchiveError(t *testing.T) {
+	archive := newTestArchive(t, http.StatusBadRequest, `{"error": true, "reason": "Parameter 'start_date' is out of allowed range"}`)
+
+	_, err := archive.GetDailyWeather(context.Background(), time.Now())
+
+	if err == nil || err.Error() != "openmeteo: request failed with 400 Bad Request: Parameter 'start_date' is out of allowed range" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestArchiveLocation(t *testing.T) {
+	archive := newTestArchive(t, http.StatusOK, testArchive)
+
+	tz, err := archive.GetLocation(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tz.String() != "America/Chicago" {
+		t.Errorf("Expected the timezone Open-Meteo picked, got %s", tz)
+	}
+}
@@ -0,0 +1,120 @@
+package openmeteo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+var GEOCODING_API_URL *url.URL
+
+type Geocoder struct {
+	// Defaults to GEOCODING_API_URL
+	BaseUrl *url.URL
+	// Optional ISO-3166-1 alpha2 code to narrow results, ex: US
+	CountryCode string
+}
+
+type geocodingResponse struct {
+	Results []*GeocodingResult `json:"results"`
+}
+
+type GeocodingResult struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Timezone  string  `json:"timezone"`
+	Admin1    string  `json:"admin1"`
+}
+
+func NewGeocoder(countryCode string) *Geocoder {
+	return &Geocoder{
+		BaseUrl:     GEOCODING_API_URL,
+		CountryCode: countryCode,
+	}
+}
+
+/**
+ * Looks up a city name or postal code
+ * @see https://open-meteo.com/en/docs/geocoding-api
+ */
+func (g *Geocoder) Search(ctx context.Context, place string) (*GeocodingResult, error) {
+	url := *g.BaseUrl
+	query := url.Query()
+
+	query.Add("name", place)
+	query.Add("count", "1")
+
+	if g.CountryCode != "" {
+		query.Add("countryCode", g.CountryCode)
+	}
+
+	url.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+
+	if err != nil {
+		log.Printf("Could not create request %s", err)
+		return nil, err
+	}
+
+	res, err := httpClient.Do(req)
+
+	if err != nil {
+		log.Printf("Error making request: %s", err)
+		return nil, err
+	}
+
+	defer res.Body.Close()
+	resBody, err := ioutil.ReadAll(res.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("openmeteo: geocoding request failed with %s", res.Status)
+	}
+
+	var geocoding geocodingResponse
+	err = json.Unmarshal(resBody, &geocoding)
+
+	if err != nil {
+		log.Printf("Could not parse response body %s", err)
+		return nil, err
+	}
+
+	if len(geocoding.Results) == 0 {
+		return nil, errors.New("openmeteo: no location found for " + place)
+	}
+
+	return geocoding.Results[0], nil
+}
+
+func (g *Geocoder) Geocode(ctx context.Context, place string) (float64, float64, error) {
+	result, err := g.Search(ctx, place)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	log.Printf("Found %s, %s (%f,%f) for %s", result.Name, result.Admin1, result.Latitude, result.Longitude, place)
+
+	return result.Latitude, result.Longitude, nil
+}
+
+func init() {
+	geocodingUrl, err := url.Parse("https://geocoding-api.open-meteo.com/v1/search")
+
+	if err != nil {
+		log.Fatalf("Cannot parse url %v", err)
+		return
+	}
+
+	GEOCODING_API_URL = geocodingUrl
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/blanket"
+	"github.com/colevoss/temperature-blanket/metar"
+	"github.com/colevoss/temperature-blanket/noaa"
+	"github.com/colevoss/temperature-blanket/nws"
+	"github.com/colevoss/temperature-blanket/openmeteo"
+	"github.com/colevoss/temperature-blanket/pws"
+	"github.com/colevoss/temperature-blanket/replay"
+	"github.com/colevoss/temperature-blanket/synoptic"
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+/**
+ * Builds the weather source for a blanket, cached on disk when a cache
+ * directory is configured
+ */
+func newWeather(ctx context.Context, config *blanket.BlanketConfig) (weather.Weather, error) {
+	w, err := newProviders(ctx, config)
+
+	if err != nil || config.CacheDir == "" {
+		return w, err
+	}
+
+	cache := weather.NewCache(w, weather.NewDiskCache(config.CacheDir))
+
+	cache.Location, err = loadTimezone(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+/**
+ * When more than one provider is configured they are wrapped in a failover
+ * and tried in order
+ */
+func newProviders(ctx context.Context, config *blanket.BlanketConfig) (weather.Weather, error) {
+	if len(config.Providers) == 0 {
+		return newSynopticApi(ctx, config, &blanket.ProviderConfig{})
+	}
+
+	providers := []weather.Weather{}
+
+	for _, providerConfig := range config.Providers {
+		provider, err := newProvider(ctx, config, providerConfig)
+
+		if err != nil {
+			return nil, err
+		}
+
+		providers = append(providers, provider)
+	}
+
+	if len(providers) == 1 {
+		return providers[0], nil
+	}
+
+	return weather.NewFailover(providers...), nil
+}
+
+func newProvider(ctx context.Context, config *blanket.BlanketConfig, providerConfig *blanket.ProviderConfig) (weather.Weather, error) {
+	switch providerConfig.Type {
+	case "synoptic":
+		return newSynopticApi(ctx, config, providerConfig)
+	case "openmeteo":
+		return newOpenMeteo(ctx, config)
+	case "noaa":
+		return newNOAA(config, providerConfig)
+	case "metar":
+		return newMETAR(config, providerConfig)
+	case "nws":
+		return newNWS(config, providerConfig)
+	case "pws":
+		return newPWS(config, providerConfig)
+	case "replay":
+		return newReplay(config, providerConfig)
+	default:
+		return nil, fmt.Errorf("unknown weather provider %q", providerConfig.Type)
+	}
+}
+
+/**
+ * Uses the configured coordinates, geocoding the configured location when
+ * there are none. Without a configured timezone the geocoded place's is used,
+ * otherwise the archive looks up the one Open-Meteo picks.
+ */
+func newOpenMeteo(ctx context.Context, config *blanket.BlanketConfig) (*openmeteo.Archive, error) {
+	tz, err := loadTimezone(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var archive *openmeteo.Archive
+
+	if config.Latitude != nil && config.Longitude != nil {
+		archive = openmeteo.New(*config.Latitude, *config.Longitude)
+	} else if config.Location != "" {
+		result, err := openmeteo.NewGeocoder(config.Country).Search(ctx, config.Location)
+
+		if err != nil {
+			return nil, err
+		}
+
+		archive = openmeteo.New(result.Latitude, result.Longitude)
+
+		if tz == nil && result.Timezone != "" {
+			tz, err = time.LoadLocation(result.Timezone)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		return nil, fmt.Errorf("openmeteo requires a location or latitude/longitude")
+	}
+
+	archive.Location = tz
+
+	return archive, nil
+}
+
+func newNOAA(config *blanket.BlanketConfig, providerConfig *blanket.ProviderConfig) (*noaa.File, error) {
+	if providerConfig.Path == "" {
+		return nil, fmt.Errorf("noaa requires a path to a .dly or .csv file")
+	}
+
+	file := noaa.New(providerConfig.Path)
+	file.Metric = providerConfig.Metric
+	file.StationId = providerConfig.StationId
+
+	tz, err := loadTimezone(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	file.Location = tz
+
+	return file, nil
+}
+
+func newMETAR(config *blanket.BlanketConfig, providerConfig *blanket.ProviderConfig) (*metar.Feed, error) {
+	if providerConfig.Path == "" {
+		return nil, fmt.Errorf("metar requires a path or url to read reports from")
+	}
+
+	feed := metar.New(providerConfig.Path)
+	feed.Station = providerConfig.StationId
+
+	tz, err := loadTimezone(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	feed.Location = tz
+
+	return feed, nil
+}
+
+func newNWS(config *blanket.BlanketConfig, providerConfig *blanket.ProviderConfig) (*nws.Climate, error) {
+	if providerConfig.Path == "" {
+		return nil, fmt.Errorf("nws requires a path or url to read climate reports from")
+	}
+
+	climate := nws.New(providerConfig.Path)
+
+	tz, err := loadTimezone(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	climate.Location = tz
+
+	return climate, nil
+}
+
+func newPWS(config *blanket.BlanketConfig, providerConfig *blanket.ProviderConfig) (*pws.Station, error) {
+	if providerConfig.Path == "" {
+		return nil, fmt.Errorf("pws requires the path of the receiver's store")
+	}
+
+	station := pws.New(pws.NewFileStore(providerConfig.Path), providerConfig.StationId)
+
+	tz, err := loadTimezone(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	station.Location = tz
+
+	return station, nil
+}
+
+func newReplay(config *blanket.BlanketConfig, providerConfig *blanket.ProviderConfig) (*replay.File, error) {
+	if providerConfig.Path == "" {
+		return nil, fmt.Errorf("replay requires a path to a .csv or .jsonl file")
+	}
+
+	file := replay.New(providerConfig.Path)
+	file.Metric = providerConfig.Metric
+
+	tz, err := loadTimezone(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	file.Location = tz
+
+	return file, nil
+}
+
+// The blanket's configured timezone or nil when there isn't one
+func loadTimezone(config *blanket.BlanketConfig) (*time.Location, error) {
+	if config.Timezone == "" {
+		return nil, nil
+	}
+
+	return time.LoadLocation(config.Timezone)
+}
+
+/**
+ * Uses the provider's station id, then the blanket's, when either is set.
+ * Otherwise the nearest good station to the configured coordinates or
+ * location (ZIP code or city) is picked.
+ */
+func newSynopticApi(ctx context.Context, config *blanket.BlanketConfig, providerConfig *blanket.ProviderConfig) (*synoptic.SynopticApi, error) {
+	strategy, err := synoptic.ParseStrategy(providerConfig.Strategy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stationId := providerConfig.StationId
+
+	if stationId == "" {
+		stationId = config.StationId
+	}
+
+	synopticApi, err := synopticStation(ctx, config, stationId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	synopticApi.Strategy = strategy
+	synopticApi.CrossCheck = providerConfig.CrossCheck
+
+	return synopticApi, nil
+}
+
+/**
+ * Uses the configured station, discovering the nearest one when there is
+ * none. The configured timezone is used over the station's.
+ */
+func synopticStation(ctx context.Context, config *blanket.BlanketConfig, stationId string) (*synoptic.SynopticApi, error) {
+	synopticApi := synoptic.New(stationId)
+
+	if stationId == "" {
+		station, err := discoverStation(ctx, config)
+
+		if err != nil {
+			return nil, fmt.Errorf("could not discover a station: %w", err)
+		}
+
+		if station != nil {
+			log.Printf("Using station %s (%s) %.1f miles away", station.Stid, station.Name, station.Distance)
+			synopticApi = synoptic.NewForStation(station)
+		}
+	}
+
+	if config.Timezone != "" {
+		tz, err := time.LoadLocation(config.Timezone)
+
+		if err != nil {
+			log.Printf("Cannot load timezone %s, using the station's timezone", err)
+		} else {
+			synopticApi.Location = tz
+		}
+	}
+
+	return synopticApi, nil
+}
+
+func discoverStation(ctx context.Context, config *blanket.BlanketConfig) (*synoptic.Station, error) {
+	discovery := synoptic.NewDiscovery(openmeteo.NewGeocoder(config.Country))
+
+	if config.Latitude != nil && config.Longitude != nil {
+		stations, err := discovery.FindStations(ctx, *config.Latitude, *config.Longitude)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return stations[0], nil
+	}
+
+	if config.Location != "" {
+		return discovery.NearestStation(ctx, config.Location)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,73 @@
+package pws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+// Daily weather from the observations a personal weather station pushed to a Receiver
+type Station struct {
+	Store Store
+	// Id (or Ecowitt PASSKEY) of the station. When empty every stored
+	// observation is used
+	StationId string
+	// Timezone the day is computed in. Defaults to the local timezone
+	Location *time.Location
+}
+
+func New(store Store, stationId string) *Station {
+	return &Station{
+		Store:     store,
+		StationId: stationId,
+	}
+}
+
+func (s *Station) Name() string {
+	if s.StationId == "" {
+		return "pws"
+	}
+
+	return "pws/" + s.StationId
+}
+
+func (s *Station) GetLocation(ctx context.Context) (*time.Location, error) {
+	if s.Location == nil {
+		return time.Local, nil
+	}
+
+	return s.Location, nil
+}
+
+func (s *Station) GetDailyWeather(ctx context.Context, date time.Time) (*weather.WeatherInfo, error) {
+	tz, _ := s.GetLocation(ctx)
+	day := weather.CalendarDay(date, tz)
+
+	observations, err := s.Store.Observations(s.StationId, day.Start, day.End)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(observations) == 0 {
+		return nil, fmt.Errorf("pws: no observations for %s", day.Start.Format("Jan 2 2006"))
+	}
+
+	weatherInfo, err := weather.Summarize(day.Start, observations)
+
+	if err != nil {
+		return nil, err
+	}
+
+	weatherInfo.Provider = s.Name()
+
+	return weatherInfo, nil
+}
+
+func (s *Station) GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*weather.WeatherInfo, error) {
+	tz, _ := s.GetLocation(ctx)
+
+	return weather.EachDay(ctx, from, to, tz, s.GetDailyWeather)
+}
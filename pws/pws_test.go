@@ -0,0 +1,78 @@
+package pws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReceiver(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "pws.jsonl"))
+	server := httptest.NewServer(NewReceiver(store, "secret"))
+
+	defer server.Close()
+
+	// Weather Underground / Ambient
+	for _, upload := range []string{
+		"ID=backyard&PASSWORD=secret&dateutc=2023-01-10+05:30:00&tempf=20.5&humidity=80&action=updateraw",
+		"ID=backyard&PASSWORD=secret&dateutc=2023-01-10+18:30:00&tempf=50.5&action=updateraw",
+		"ID=backyard&PASSWORD=secret&dateutc=2023-01-11+07:30:00&tempf=99&action=updateraw",
+	} {
+		res, err := http.Get(server.URL + "/weatherstation/updateweatherstation.php?" + upload)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Unexpected status %s for %s", res.Status, upload)
+		}
+	}
+
+	// Ecowitt
+	res, err := http.PostForm(server.URL+"/data/report/", url.Values{
+		"PASSKEY":     {"secret"},
+		"stationtype": {"GW1000B_V1.6.8"},
+		"dateutc":     {"2023-01-10 12:30:00"},
+		"tempf":       {"32.0"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected status %s", res.Status)
+	}
+
+	res, _ = http.Get(server.URL + "/weatherstation/updateweatherstation.php?ID=backyard&PASSWORD=wrong&tempf=10")
+
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a bad key to be rejected, got %s", res.Status)
+	}
+
+	station := New(store, "")
+	station.Location, _ = time.LoadLocation("America/Chicago")
+
+	info, err := station.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The 05:30 UTC upload is the 9th in Chicago and 07:30 on the 11th is the 11th
+	if info.High != 50.5 || info.Low != 32 || info.Average != 41.25 {
+		t.Errorf("Unexpected weather %+v", info)
+	}
+
+	backyard, _ := store.Observations("backyard", time.Time{}, time.Now())
+
+	if len(backyard) != 3 || !strings.HasPrefix(New(store, "backyard").Name(), "pws/backyard") {
+		t.Errorf("Expected 3 observations for backyard, got %d", len(backyard))
+	}
+}
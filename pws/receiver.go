@@ -0,0 +1,130 @@
+package pws
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+const uploadDateFormat = "2006-01-02 15:04:05"
+
+/**
+ * Accepts uploads from personal weather stations using the Weather
+ * Underground protocol (GET updateweatherstation.php?ID=&PASSWORD=&tempf=)
+ * that Ambient stations also speak, or Ecowitt's customized server protocol
+ * (form POST with PASSKEY and tempf). Any path is accepted.
+ */
+type Receiver struct {
+	Store Store
+	// When set, uploads must send it as PASSWORD (Weather Underground) or
+	// PASSKEY (Ecowitt/Ambient)
+	Key string
+}
+
+func NewReceiver(store Store, key string) *Receiver {
+	return &Receiver{
+		Store: store,
+		Key:   key,
+	}
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var values url.Values
+
+	switch req.Method {
+	case http.MethodGet:
+		values = req.URL.Query()
+	case http.MethodPost:
+		if err := req.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		values = req.Form
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	station, observation, err := r.parseUpload(values)
+
+	if err == errUnauthorized {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err != nil {
+		log.Printf("Rejected upload: %s", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := r.Store.Add(station, observation); err != nil {
+		log.Printf("Could not store observation: %s", err)
+		http.Error(w, "could not store observation", http.StatusInternalServerError)
+		return
+	}
+
+	// Weather Underground clients look for this exact body
+	fmt.Fprintln(w, "success")
+}
+
+var errUnauthorized = fmt.Errorf("pws: invalid station key")
+
+func (r *Receiver) parseUpload(values url.Values) (string, weather.Observation, error) {
+	observation := weather.Observation{}
+
+	// Weather Underground and Ambient send ID/PASSWORD, Ecowitt sends PASSKEY
+	station := values.Get("ID")
+	key := values.Get("PASSWORD")
+
+	if passkey := values.Get("PASSKEY"); passkey != "" {
+		key = passkey
+
+		if station == "" {
+			station = passkey
+		}
+	}
+
+	if r.Key != "" && key != r.Key {
+		return "", observation, errUnauthorized
+	}
+
+	tempf := values.Get("tempf")
+
+	if tempf == "" {
+		return "", observation, fmt.Errorf("pws: upload has no tempf")
+	}
+
+	temp, err := strconv.ParseFloat(tempf, 64)
+
+	if err != nil {
+		return "", observation, fmt.Errorf("pws: invalid tempf %q", tempf)
+	}
+
+	// Stations send -9999 when the sensor is offline
+	if temp <= -9999 {
+		return "", observation, fmt.Errorf("pws: sensor reported no temperature")
+	}
+
+	observedAt := time.Now().UTC()
+	dateutc := values.Get("dateutc")
+
+	if dateutc != "" && dateutc != "now" {
+		observedAt, err = time.Parse(uploadDateFormat, dateutc)
+
+		if err != nil {
+			return "", observation, fmt.Errorf("pws: invalid dateutc %q", dateutc)
+		}
+	}
+
+	observation.Time = observedAt
+	observation.Temperature = temp
+
+	return station, observation, nil
+}
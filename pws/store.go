@@ -0,0 +1,151 @@
+package pws
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+// Where observations pushed by a station are kept
+type Store interface {
+	Add(station string, observation weather.Observation) error
+	// Observations for the station in [start, end), oldest first
+	Observations(station string, start time.Time, end time.Time) ([]weather.Observation, error)
+}
+
+type storedObservation struct {
+	Station     string    `json:"station"`
+	Time        time.Time `json:"time"`
+	Temperature float64   `json:"temperature"`
+}
+
+type MemoryStore struct {
+	mu           sync.Mutex
+	observations []storedObservation
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (m *MemoryStore) Add(station string, observation weather.Observation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.observations = append(m.observations, storedObservation{
+		Station:     station,
+		Time:        observation.Time,
+		Temperature: observation.Temperature,
+	})
+
+	return nil
+}
+
+func (m *MemoryStore) Observations(station string, start time.Time, end time.Time) ([]weather.Observation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return between(m.observations, station, start, end), nil
+}
+
+// Appends observations to a JSON Lines file so they survive restarts
+type FileStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		Path: path,
+	}
+}
+
+func (f *FileStore) Add(station string, observation weather.Observation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	line, err := json.Marshal(storedObservation{
+		Station:     station,
+		Time:        observation.Time.UTC(),
+		Temperature: observation.Temperature,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(line, '\n'))
+
+	return err
+}
+
+func (f *FileStore) Observations(station string, start time.Time, end time.Time) ([]weather.Observation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.Open(f.Path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	observations := []storedObservation{}
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		var observation storedObservation
+
+		if err := json.Unmarshal(scanner.Bytes(), &observation); err != nil {
+			continue
+		}
+
+		observations = append(observations, observation)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return between(observations, station, start, end), nil
+}
+
+func between(stored []storedObservation, station string, start time.Time, end time.Time) []weather.Observation {
+	observations := []weather.Observation{}
+
+	for _, observation := range stored {
+		if station != "" && observation.Station != station {
+			continue
+		}
+
+		if observation.Time.Before(start) || !observation.Time.Before(end) {
+			continue
+		}
+
+		observations = append(observations, weather.Observation{
+			Time:        observation.Time,
+			Temperature: observation.Temperature,
+		})
+	}
+
+	sort.SliceStable(observations, func(i, j int) bool {
+		return observations[i].Time.Before(observations[j].Time)
+	})
+
+	return observations
+}
@@ -0,0 +1,388 @@
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+// Column/field names recognized for the timestamp and the temperature, in
+// the order they are picked when a file has more than one
+var timeNames = []string{"time", "timestamp", "date_time", "datetime", "date"}
+var temperatureNames = []string{"temperature", "temp", "air_temp", "tempf", "tempc"}
+
+// Layouts tried, in order, for timestamps without a zone offset
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"01/02/2006 15:04:05",
+	"01/02/2006 15:04",
+}
+
+/**
+ * Replays timestamped temperature observations saved in a CSV file (with a
+ * header row) or a JSON Lines file. Observations are summarized the same way
+ * as live data.
+ */
+type File struct {
+	Path string
+	// Temperatures in the file are celsius rather than fahrenheit. tempf and
+	// tempc are always read in their own unit
+	Metric bool
+	// Timezone the day is computed in and that timestamps without an offset
+	// are read in. Defaults to UTC
+	Location *time.Location
+
+	once         sync.Once
+	err          error
+	observations []weather.Observation
+}
+
+func New(path string) *File {
+	return &File{
+		Path: path,
+	}
+}
+
+func (f *File) Name() string {
+	return "replay/" + filepath.Base(f.Path)
+}
+
+// Files with the same name in different directories are kept apart
+func (f *File) CacheKey() string {
+	path, err := filepath.Abs(f.Path)
+
+	if err != nil {
+		path = f.Path
+	}
+
+	return fmt.Sprintf("replay/%s/metric=%t", path, f.Metric)
+}
+
+func (f *File) location() *time.Location {
+	if f.Location == nil {
+		return time.UTC
+	}
+
+	return f.Location
+}
+
+func (f *File) GetLocation(ctx context.Context) (*time.Location, error) {
+	return f.location(), nil
+}
+
+func (f *File) GetDailyWeather(ctx context.Context, date time.Time) (*weather.WeatherInfo, error) {
+	day := weather.CalendarDay(date, f.location())
+
+	all, err := f.Observations()
+
+	if err != nil {
+		return nil, err
+	}
+
+	observations := []weather.Observation{}
+
+	for _, observation := range all {
+		if day.Contains(observation.Time) {
+			observations = append(observations, observation)
+		}
+	}
+
+	if len(observations) == 0 {
+		return nil, fmt.Errorf("replay: no observations for %s in %s", day.Start.Format("Jan 2 2006"), f.Path)
+	}
+
+	weatherInfo, err := weather.Summarize(day.Start, observations)
+
+	if err != nil {
+		return nil, err
+	}
+
+	weatherInfo.Provider = f.Name()
+
+	return weatherInfo, nil
+}
+
+func (f *File) GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*weather.WeatherInfo, error) {
+	return weather.EachDay(ctx, from, to, f.location(), f.GetDailyWeather)
+}
+
+// Every observation in the file in fahrenheit, oldest first. The file is only read once.
+func (f *File) Observations() ([]weather.Observation, error) {
+	f.once.Do(func() {
+		f.observations, f.err = f.load()
+
+		if f.err == nil {
+			log.Printf("Loaded %d observations from %s", len(f.observations), f.Path)
+		}
+	})
+
+	return f.observations, f.err
+}
+
+func (f *File) load() ([]weather.Observation, error) {
+	file, err := os.Open(f.Path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var observations []weather.Observation
+
+	switch strings.ToLower(filepath.Ext(f.Path)) {
+	case ".csv":
+		observations, err = f.parseCSV(file)
+	case ".jsonl", ".ndjson", ".json":
+		observations, err = f.parseJSONLines(file)
+	default:
+		return nil, fmt.Errorf("replay: unsupported file type %s", f.Path)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(observations, func(i, j int) bool {
+		return observations[i].Time.Before(observations[j].Time)
+	})
+
+	return observations, nil
+}
+
+func (f *File) parseCSV(r io.Reader) ([]weather.Observation, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+
+	hasColumn := func(name string) bool {
+		_, ok := columns[name]
+		return ok
+	}
+
+	timeName, timeOk := first(timeNames, hasColumn)
+	temperatureName, temperatureOk := first(temperatureNames, hasColumn)
+
+	if !timeOk || !temperatureOk {
+		return nil, errors.New("replay: CSV header needs a time and a temperature column")
+	}
+
+	timeColumn, temperatureColumn := columns[timeName], columns[temperatureName]
+	metric := f.metric(temperatureName)
+
+	observations := []weather.Observation{}
+
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if timeColumn >= len(row) || temperatureColumn >= len(row) {
+			return nil, fmt.Errorf("replay: line %d is missing columns", line)
+		}
+
+		value := strings.TrimSpace(row[temperatureColumn])
+
+		// Loggers leave the cell empty when a reading was missed
+		if value == "" {
+			continue
+		}
+
+		temp, err := strconv.ParseFloat(value, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("replay: line %d has an invalid temperature %q", line, value)
+		}
+
+		observedAt, err := f.parseTime(row[timeColumn])
+
+		if err != nil {
+			return nil, fmt.Errorf("replay: line %d: %w", line, err)
+		}
+
+		observations = append(observations, observation(observedAt, temp, metric))
+	}
+
+	return observations, nil
+}
+
+func (f *File) parseJSONLines(r io.Reader) ([]weather.Observation, error) {
+	observations := []weather.Observation{}
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var fields map[string]interface{}
+
+		if err := json.Unmarshal(scanner.Bytes(), &fields); err != nil {
+			return nil, fmt.Errorf("replay: line %d: %w", line, err)
+		}
+
+		observation, ok, err := f.parseFields(fields)
+
+		if err != nil {
+			return nil, fmt.Errorf("replay: line %d: %w", line, err)
+		}
+
+		if ok {
+			observations = append(observations, observation)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return observations, nil
+}
+
+// ok is false when the temperature is null
+func (f *File) parseFields(fields map[string]interface{}) (weather.Observation, bool, error) {
+	lowered := map[string]interface{}{}
+
+	for key, value := range fields {
+		lowered[strings.ToLower(key)] = value
+	}
+
+	hasField := func(name string) bool {
+		_, ok := lowered[name]
+		return ok
+	}
+
+	timeName, ok := first(timeNames, hasField)
+
+	if !ok {
+		return weather.Observation{}, false, errors.New("no time field")
+	}
+
+	var observedAt time.Time
+	var err error
+
+	switch value := lowered[timeName].(type) {
+	case string:
+		observedAt, err = f.parseTime(value)
+	case float64:
+		observedAt = time.Unix(int64(value), 0)
+	default:
+		err = fmt.Errorf("invalid time %v", value)
+	}
+
+	if err != nil {
+		return weather.Observation{}, false, err
+	}
+
+	temperatureName, ok := first(temperatureNames, hasField)
+
+	if !ok {
+		return weather.Observation{}, false, errors.New("no temperature field")
+	}
+
+	value := lowered[temperatureName]
+
+	if value == nil {
+		return weather.Observation{}, false, nil
+	}
+
+	temp, ok := value.(float64)
+
+	if !ok {
+		return weather.Observation{}, false, fmt.Errorf("invalid temperature %v", value)
+	}
+
+	return observation(observedAt, temp, f.metric(temperatureName)), true, nil
+}
+
+func (f *File) parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if observedAt, err := time.Parse(time.RFC3339, value); err == nil {
+		return observedAt, nil
+	}
+
+	for _, layout := range timeLayouts {
+		if observedAt, err := time.ParseInLocation(layout, value, f.location()); err == nil {
+			return observedAt, nil
+		}
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+// Whether the temperature column or field is in celsius
+func (f *File) metric(name string) bool {
+	switch name {
+	case "tempf":
+		return false
+	case "tempc":
+		return true
+	default:
+		return f.Metric
+	}
+}
+
+func observation(observedAt time.Time, temp float64, metric bool) weather.Observation {
+	if metric {
+		temp = weather.CelciusToFahrenheit(temp)
+	}
+
+	return weather.Observation{
+		Time:        observedAt,
+		Temperature: temp,
+	}
+}
+
+// The first of names, in order, that the file has
+func first(names []string, has func(name string) bool) (string, bool) {
+	for _, name := range names {
+		if has(name) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
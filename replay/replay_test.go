@@ -0,0 +1,108 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name string, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestCSV(t *testing.T) {
+	path := writeFile(t, "logger.csv", `Timestamp,Temp,Humidity
+2023-01-09 23:55:00,10,80
+2023-01-10 00:00:00,20,80
+2023-01-10 12:00:00,,80
+2023-01-10 18:00:00,40,70
+2023-01-11T00:00:00-06:00,90,70
+`)
+
+	file := New(path)
+	file.Location, _ = time.LoadLocation("America/Chicago")
+
+	info, err := file.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.High != 40 || info.Low != 20 || info.Average != 30 || info.Provider != "replay/logger.csv" {
+		t.Errorf("Unexpected weather %+v", info)
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	path := writeFile(t, "saved.jsonl", `{"time": "2023-01-10T06:00:00Z", "temperature": -3.0}
+{"time": "2023-01-10T12:00:00Z", "temperature": null}
+
+{"time": "2023-01-10T18:00:00Z", "temperature": 13.0}
+`)
+
+	file := New(path)
+	file.Metric = true
+
+	info, err := file.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprintf("%.1f/%.1f/%.1f", info.High, info.Low, info.Average) != "55.4/26.6/41.0" {
+		t.Errorf("Unexpected weather %+v", info)
+	}
+
+	_, err = file.GetDailyWeather(context.Background(), time.Date(2023, 2, 10, 0, 0, 0, 0, time.UTC))
+
+	if err == nil {
+		t.Errorf("Expected an error for a day without observations")
+	}
+}
+
+func TestCelsiusColumn(t *testing.T) {
+	file := New(writeFile(t, "logger.csv", `time,tempc
+2023-01-10 06:00:00,-5
+2023-01-10 18:00:00,10
+`))
+
+	info, err := file.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.High != 50 || info.Low != 23 {
+		t.Errorf("Expected tempc to be read as celsius, got %+v", info)
+	}
+}
+
+func TestFieldPriority(t *testing.T) {
+	lines := ""
+
+	// Enough lines that a field picked at random would show up
+	for i := 0; i < 20; i++ {
+		lines += fmt.Sprintf(`{"date": "2023-02-10", "tempf": 0, "time": "2023-01-10T%02d:00:00Z", "temp": 50}`+"\n", i)
+	}
+
+	file := New(writeFile(t, "saved.jsonl", lines))
+
+	info, err := file.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.High != 50 || info.Low != 50 || info.Coverage.Observations != 20 {
+		t.Errorf("Expected time and temp to be used, got %+v", info)
+	}
+}
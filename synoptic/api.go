@@ -1,12 +1,14 @@
 package synoptic
 
 import (
+	"context"
 	"encoding/json"
-	"io/ioutil"
+	"fmt"
+	"io"
 	"log"
-	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/colevoss/temperature-blanket/weather"
@@ -15,107 +17,241 @@ import (
 var SYNOPTIC_API_TOKEN string
 var SYNOPTIC_API_URL *url.URL
 
+// Lincoln Municipal Airport, used when no station is configured
+const DefaultStationId = "klnk"
+
+// Used when MaxRequestDays is not set
+const DefaultMaxRequestDays = 180
+
 type SynopticApi struct {
+	// Root of the Synoptic v2 API. Defaults to SYNOPTIC_API_URL
+	BaseUrl *url.URL
+	// Synoptic station id (STID) to gather observations from
+	StationId string
+	// Timezone the day window is computed in. When nil the station's own
+	// TIMEZONE reported by Synoptic is used.
+	Location *time.Location
+	// Most days of observations fetched in a single timeseries request when
+	// getting a range of days. A window's readings are held in memory until
+	// it is summarized. Defaults to DefaultMaxRequestDays when not set
+	MaxRequestDays int
+	// Ask Synoptic to run its quality control and drop the readings it flags
+	QC bool
+	// Checks readings have to pass before they are summarized
+	Filter weather.Filter
+	// Whether days are summarized from every reading or by Synoptic.
+	// Defaults to Timeseries
+	Strategy Strategy
+	// Also fetch every reading when using Statistics and compare the two
+	CrossCheck bool
+	// Degrees fahrenheit the two strategies can differ by before it is logged
+	CrossCheckTolerance float64
+	// Makes the requests. Defaults to NewClient
+	Client *Client
+}
+
+func New(stationId string) *SynopticApi {
+	if stationId == "" {
+		stationId = DefaultStationId
+	}
+
+	return &SynopticApi{
+		BaseUrl:             SYNOPTIC_API_URL,
+		StationId:           stationId,
+		MaxRequestDays:      DefaultMaxRequestDays,
+		QC:                  true,
+		Filter:              weather.DefaultFilter,
+		Strategy:            Timeseries,
+		CrossCheckTolerance: 1,
+		Client:              NewClient(),
+	}
 }
 
-func New() *SynopticApi {
-	return &SynopticApi{}
+/**
+ * Creates an api for a station returned from station discovery. The station's
+ * timezone is used for the day window so it does not need to be looked up.
+ */
+func NewForStation(station *Station) *SynopticApi {
+	api := New(station.Stid)
+
+	if tz, err := time.LoadLocation(station.Timezone); err == nil {
+		api.Location = tz
+	}
+
+	return api
 }
 
-func (s *SynopticApi) GetPreviousDaysWeatherInfo(day time.Time) (*weather.WeatherInfo, error) {
-	start, end := s.GetPreviousDay(day)
+func (s *SynopticApi) Name() string {
+	return "synoptic/" + s.StationId
+}
 
-	timeseriesData, err := s.GetTemparatureData(start.UTC(), end.UTC())
+func (s *SynopticApi) GetDailyWeather(ctx context.Context, date time.Time) (*weather.WeatherInfo, error) {
+	tz, err := s.GetLocation(ctx)
 
 	if err != nil {
 		return nil, err
 	}
 
-	station := timeseriesData.Station[0]
-	temps := station.Observations.AirTemp
+	day := weather.CalendarDay(date, tz)
+	weatherInfos, err := s.window(ctx, []time.Time{day.Start}, tz)
 
-	total := 0.0
-	high := 0.0
-	low := 0.0
+	if err != nil {
+		return nil, err
+	}
 
-	for i, temp := range temps {
-		fTemp := weather.CelciusToFahrenheit(temp)
+	if len(weatherInfos) == 0 {
+		return nil, fmt.Errorf("%s: %w on %s", s.Name(), weather.ErrNoObservations, day.Start.Format("Jan 2 2006"))
+	}
 
-		if i == 0 {
-			high = fTemp
-			low = fTemp
-		}
+	return weatherInfos[0], nil
+}
+
+/**
+ * Returns one summary per local calendar day from from through to. Days are
+ * fetched in windows of up to MaxRequestDays and split up locally so a year
+ * only takes a few requests.
+ */
+func (s *SynopticApi) GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*weather.WeatherInfo, error) {
+	tz, err := s.GetLocation(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	days := weather.Days(from, to, tz)
+	weatherInfos := []*weather.WeatherInfo{}
+	maxRequestDays := s.MaxRequestDays
 
-		if fTemp > high {
-			high = fTemp
+	if maxRequestDays <= 0 {
+		maxRequestDays = DefaultMaxRequestDays
+	}
+
+	for i := 0; i < len(days); i += maxRequestDays {
+		end := i + maxRequestDays
+
+		if end > len(days) {
+			end = len(days)
 		}
 
-		if fTemp < low {
-			low = fTemp
+		windowInfos, err := s.window(ctx, days[i:end], tz)
+
+		if err != nil {
+			return nil, err
 		}
 
-		total += fTemp
+		weatherInfos = append(weatherInfos, windowInfos...)
 	}
 
-	avg := total / float64(len(temps))
-
-	weatherInfo := &weather.WeatherInfo{
-		Date:    start,
-		High:    high,
-		Low:     low,
-		Average: avg,
+	if len(weatherInfos) == 0 {
+		return nil, weather.ErrNoDays
 	}
 
-	return weatherInfo, nil
+	return weatherInfos, nil
 }
 
-/**
- * Given a day, return 00:00 and 23:59 of the previous day
- */
-func (s *SynopticApi) GetPreviousDay(day time.Time) (time.Time, time.Time) {
-	tz, err := time.LoadLocation("America/Chicago")
+// Summaries for consecutive days, fetched with the api's Strategy
+func (s *SynopticApi) window(ctx context.Context, days []time.Time, tz *time.Location) ([]*weather.WeatherInfo, error) {
+	if s.Strategy != Statistics {
+		return s.timeseriesWindow(ctx, days, tz)
+	}
+
+	statistics, err := s.statisticsWindow(ctx, days, tz)
 
 	if err != nil {
-		log.Fatalf("Cannot load timezone %s", err)
+		log.Printf("%s: statistics failed, falling back to timeseries: %s", s.Name(), err)
+		return s.timeseriesWindow(ctx, days, tz)
 	}
 
-	// now := time.Now()
-	yesterday := day.Add(time.Hour * -24)
+	if len(statistics) == len(days) && !s.CrossCheck {
+		return statistics, nil
+	}
 
-	startOfYesterday := time.Date(
-		yesterday.Year(),
-		yesterday.Month(),
-		yesterday.Day(),
-		0,
-		0,
-		0,
-		0,
-		tz,
-	)
+	// Some days are missing from the statistics, or they are being checked,
+	// so the readings are needed as well
+	timeseries, err := s.timeseriesWindow(ctx, days, tz)
 
-	endOfYesterday := startOfYesterday.Add(time.Hour * 23).Add(time.Minute * 59)
+	if err != nil {
+		if len(statistics) == 0 {
+			return nil, err
+		}
+
+		log.Printf("%s: timeseries failed, using statistics alone: %s", s.Name(), err)
 
-	return startOfYesterday, endOfYesterday
+		return statistics, nil
+	}
+
+	return s.crossCheck(days, statistics, timeseries), nil
 }
 
 /**
- * Makes request to get temperature data
- * @see https://developers.synopticdata.com/mesonet/v2/stations/timeseries/
+ * Downloads every reading for the days and summarizes them locally. The
+ * response is decoded as it arrives, but the window's air temperatures are
+ * kept until it is summarized, so memory grows with the number of days.
  */
-func (s *SynopticApi) GetTemparatureData(start time.Time, end time.Time) (*SynopticTimeSeriesResponse, error) {
-	url, err := url.Parse("https://api.synopticdata.com/v2/stations/timeseries")
+func (s *SynopticApi) timeseriesWindow(ctx context.Context, days []time.Time, tz *time.Location) ([]*weather.WeatherInfo, error) {
+	// Synoptic's end is inclusive, readings at the next midnight are left out
+	// when the days are summarized
+	windowEnd := weather.DayOf(days[len(days)-1], tz).End
+
+	var stream *timeseriesStream
+
+	decode := func(body io.Reader) (*Summary, error) {
+		stream = newTimeseriesStream(days)
+		return stream.decode(body)
+	}
+
+	err := s.stream(ctx, "timeseries", timeseriesQuery(days[0].UTC(), windowEnd.UTC()), decode)
 
 	if err != nil {
-		log.Printf("Could not parse url %v", err)
 		return nil, err
 	}
 
-	query := SYNOPTIC_API_URL.Query()
+	return s.summarize(stream)
+}
 
-	query.Add("token", SYNOPTIC_API_TOKEN)
-	query.Add("stid", "klnk")
+/**
+ * Returns the timezone days are computed in for the station. If one was not
+ * configured, the station's TIMEZONE is looked up with a small recent
+ * timeseries request and remembered for subsequent calls.
+ */
+func (s *SynopticApi) GetLocation(ctx context.Context) (*time.Location, error) {
+	if s.Location != nil {
+		return s.Location, nil
+	}
+
+	query := url.Values{}
+	query.Add("recent", "60")
 	query.Add("vars", "air_temp")
 
+	timeseriesData, err := s.getTimeSeries(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(timeseriesData.Station) == 0 {
+		return nil, fmt.Errorf("%w for station %s", ErrNoStations, s.StationId)
+	}
+
+	tz, err := time.LoadLocation(timeseriesData.Station[0].Timezone)
+
+	if err != nil {
+		log.Printf("Cannot load timezone %s", err)
+		return nil, err
+	}
+
+	s.Location = tz
+
+	return tz, nil
+}
+
+/**
+ * Query for the readings between start and end
+ * @see https://developers.synopticdata.com/mesonet/v2/stations/timeseries/
+ */
+func timeseriesQuery(start time.Time, end time.Time) url.Values {
+	query := url.Values{}
+
 	log.Printf("Date: %v - %v", start, end)
 
 	// See format docs here https://pkg.go.dev/time#Time.Format
@@ -124,40 +260,88 @@ func (s *SynopticApi) GetTemparatureData(start time.Time, end time.Time) (*Synop
 
 	query.Add("start", formattedStart)
 	query.Add("end", formattedEnd)
+	query.Add("vars", "air_temp,precip_accum,snow_interval,wind_gust,relative_humidity,dew_point_temperature")
+	query.Add("precip", "1")
+
+	return query
+}
+
+func (s *SynopticApi) getTimeSeries(ctx context.Context, params url.Values) (*SynopticTimeSeriesResponse, error) {
+	var timeSeriesResponse SynopticTimeSeriesResponse
+
+	if err := s.get(ctx, "timeseries", params, &timeSeriesResponse); err != nil {
+		return nil, err
+	}
+
+	return &timeSeriesResponse, nil
+}
+
+// Makes a request to one of the stations endpoints for the api's station
+func (s *SynopticApi) get(ctx context.Context, endpoint string, params url.Values, v summarized) error {
+	return s.stream(ctx, endpoint, params, decodeInto(v))
+}
+
+// Like get, but hands the response body to decode as it is read
+func (s *SynopticApi) stream(ctx context.Context, endpoint string, params url.Values, decode func(io.Reader) (*Summary, error)) error {
+	url := s.BaseUrl.JoinPath("stations", endpoint)
+	query := url.Query()
+
+	query.Add("token", SYNOPTIC_API_TOKEN)
+	query.Add("stid", s.StationId)
+
+	if s.QC {
+		// Flagged readings are left out, timeseries returns them as nulls
+		query.Add("qc", "on")
+		query.Add("qc_remove_data", "on")
+	}
+
+	for key, values := range params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
 
 	url.RawQuery = query.Encode()
 
 	log.Printf("Making Request to %s", url.String())
 
-	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
+	client := s.Client
 
-	if err != nil {
-		log.Printf("Could not create request %s", err)
-		return nil, err
+	if client == nil {
+		client = NewClient()
 	}
 
-	res, err := http.DefaultClient.Do(req)
-
-	if err != nil {
+	if err := client.Stream(ctx, url.String(), decode); err != nil {
 		log.Printf("Error making request: %s", err)
-		return nil, err
+		return fmt.Errorf("%s: %w", s.Name(), err)
 	}
 
-	defer res.Body.Close()
-	resBody, err := ioutil.ReadAll(res.Body)
+	return nil
+}
 
-	var timeSeriesResponse SynopticTimeSeriesResponse
-	err = json.Unmarshal(resBody, &timeSeriesResponse)
+/**
+ * Returns the readings that passed the api's Filter. Every reading left out,
+ * including those Synoptic's quality control removed, is written to the log.
+ */
+func (s *SynopticApi) filter(observations []weather.Observation, flagged []time.Time) []weather.Observation {
+	kept, rejections := s.Filter.Apply(observations)
 
-	if err != nil {
-		log.Printf("Could not parse response body %s", err)
-		return nil, err
+	for _, observedAt := range flagged {
+		log.Printf("%s: no reading at %s, missing or removed by Synoptic QC", s.Name(), observedAt.Format(time.RFC3339))
 	}
 
-	// log.Printf("%v", timeSeriesResponse.Station[0].Observations.DateTime[0])
-	// log.Printf("%v", timeSeriesResponse.Station[0].Name)
+	weather.LogRejections(s.Name(), rejections)
 
-	return &timeSeriesResponse, nil
+	return kept
+}
+
+func (station *Station) active() bool {
+	return isActive(station.Status)
+}
+
+// Stations that don't report a status are assumed to be active
+func isActive(status string) bool {
+	return status == "" || strings.EqualFold(status, "ACTIVE")
 }
 
 func Test() {
@@ -174,7 +358,7 @@ func Test() {
 
 func init() {
 	SYNOPTIC_API_TOKEN = os.Getenv("SYNOPTIC_API_TOKEN")
-	synopticUrl, err := url.Parse("https://api.synopticdata.com/v2/stations/timeseries")
+	synopticUrl, err := url.Parse("https://api.synopticdata.com/v2")
 
 	if err != nil {
 		log.Fatalf("Cannot parse url %v", err)
@@ -0,0 +1,203 @@
+package synoptic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newTestApi(t *testing.T, handler http.HandlerFunc) *SynopticApi {
+	server := httptest.NewServer(handler)
+
+	t.Cleanup(server.Close)
+
+	api := New("klnk")
+	api.BaseUrl, _ = url.Parse(server.URL)
+	api.Location, _ = time.LoadLocation("America/Chicago")
+
+	return api
+}
+
+func TestGetDailyRange(t *testing.T) {
+	requests := []string{}
+
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		requests = append(requests, query.Get("start")+"-"+query.Get("end"))
+
+		w.Write([]byte(TEST_DATA))
+	})
+
+	api.MaxRequestDays = 2
+
+	weatherInfos, err := api.GetDailyRange(
+		context.Background(),
+		time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 11, 0, 0, 0, 0, time.UTC),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedRequests := []string{"202301090600-202301110600", "202301110600-202301120600"}
+
+	if fmt.Sprint(requests) != fmt.Sprint(expectedRequests) {
+		t.Errorf("Expected requests %v, got %v", expectedRequests, requests)
+	}
+
+	// The fixture only has observations for the 10th
+	if len(weatherInfos) != 1 {
+		t.Fatalf("Expected 1 day, got %d", len(weatherInfos))
+	}
+
+	info := weatherInfos[0]
+
+	if info.Date.Format("2006-01-02") != "2023-01-10" || fmt.Sprintf("%.1f/%.1f", info.High, info.Low) != "55.4/23.0" {
+		t.Errorf("Unexpected weather %+v", info)
+	}
+}
+
+func TestGetDailyRangeWithoutMaxRequestDays(t *testing.T) {
+	requests := 0
+
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		w.Write([]byte(TEST_DATA))
+	})
+
+	api.MaxRequestDays = 0
+
+	_, err := api.GetDailyRange(
+		context.Background(),
+		time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 11, 0, 0, 0, 0, time.UTC),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+}
+
+func TestQualityControl(t *testing.T) {
+	var qc string
+
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		qc = query.Get("qc") + "/" + query.Get("qc_remove_data")
+
+		w.Write([]byte(`{
+			"STATION": [{
+				"STID": "KLNK",
+				"QC_FLAGGED": true,
+				"OBSERVATIONS": {
+					"date_time": ["2023-01-10T12:00:00Z", "2023-01-10T12:05:00Z", "2023-01-10T12:10:00Z", "2023-01-10T12:15:00Z"],
+					"air_temp_set_1": [1.0, null, 60.0, 2.0]
+				}
+			}],
+			"SUMMARY": {"RESPONSE_CODE": 1}
+		}`))
+	})
+
+	info, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if qc != "on/on" {
+		t.Errorf("Expected Synoptic QC to be requested, got %s", qc)
+	}
+
+	if fmt.Sprintf("%.1f/%.1f", info.High, info.Low) != "35.6/33.8" {
+		t.Errorf("Expected the null and the 140° reading to be left out, got %+v", info)
+	}
+}
+
+func TestConditions(t *testing.T) {
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"STATION": [{
+				"STID": "KLNK",
+				"OBSERVATIONS": {
+					"date_time": ["2023-01-10T12:00:00Z", "2023-01-10T13:00:00Z", "2023-01-10T14:00:00Z"],
+					"air_temp_set_1": [1.0, 2.0, 3.0],
+					"precip_intervals_set_1d": [2.54, null, 5.08],
+					"wind_gust_set_1": [null, 10.0, 5.0],
+					"relative_humidity_set_1": [80.0, 90.0, 100.0],
+					"dew_point_temperature_set_1d": [0.0, 0.0, 10.0]
+				}
+			}]
+		}`))
+	})
+
+	info, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Snowfall != nil {
+		t.Errorf("Expected snowfall to be absent, got %f", *info.Snowfall)
+	}
+
+	got := fmt.Sprintf(
+		"%.2f %.1f %.0f %.0f",
+		*info.Precipitation,
+		*info.WindGust,
+		*info.Humidity,
+		*info.DewPoint,
+	)
+
+	if got != "0.30 22.4 90 38" {
+		t.Errorf("Unexpected conditions %s", got)
+	}
+}
+
+func TestEnglishUnits(t *testing.T) {
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"UNITS": {
+				"air_temp": "Fahrenheit",
+				"precip_intervals": "Inches",
+				"snow_interval": "Inches",
+				"wind_gust": "Knots"
+			},
+			"STATION": [{
+				"STID": "KLNK",
+				"OBSERVATIONS": {
+					"date_time": ["2023-01-10T12:00:00Z", "2023-01-10T13:00:00Z"],
+					"air_temp_set_1": [30.0, 40.0],
+					"precip_intervals_set_1d": [0.1, 0.2],
+					"snow_interval_set_1": [1.0, null],
+					"wind_gust_set_1": [10.0, 20.0]
+				}
+			}]
+		}`))
+	})
+
+	info, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.High != 40 || info.Low != 30 {
+		t.Errorf("Expected fahrenheit readings to be used as is, got %+v", info)
+	}
+
+	got := fmt.Sprintf("%.2f %.2f %.1f", *info.Precipitation, *info.Snowfall, *info.WindGust)
+
+	if got != "0.30 1.00 23.0" {
+		t.Errorf("Expected conditions converted from the reported units, got %s", got)
+	}
+}
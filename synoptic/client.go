@@ -0,0 +1,207 @@
+package synoptic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Implemented by Synoptic responses so their SUMMARY can be checked
+type summarized interface {
+	summary() *Summary
+}
+
+/**
+ * Makes requests to the Synoptic API. Network errors, 5xx responses and 429s
+ * are retried with jittered exponential backoff. Errors Synoptic reports in a
+ * response's SUMMARY are returned as an *APIError.
+ */
+type Client struct {
+	// Defaults to a client with a 30 second timeout
+	HTTPClient *http.Client
+	// How many times a failed request is retried
+	Retries int
+	// Backoff before the first retry. Doubles with every attempt up to
+	// MaxBackoff
+	MinBackoff time.Duration
+	// Longest the client waits between attempts. A Retry-After longer than
+	// this is not waited out, the request fails with ErrRateLimited instead
+	MaxBackoff time.Duration
+}
+
+func NewClient() *Client {
+	return &Client{
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		Retries:    3,
+		MinBackoff: 500 * time.Millisecond,
+		MaxBackoff: 30 * time.Second,
+	}
+}
+
+var jitter = rand.New(rand.NewSource(time.Now().UnixNano()))
+var jitterMu sync.Mutex
+
+// Requests url and decodes the JSON response into v
+func (c *Client) Get(ctx context.Context, url string, v summarized) error {
+	return c.Stream(ctx, url, decodeInto(v))
+}
+
+// Decodes a whole response into v for Stream
+func decodeInto(v summarized) func(io.Reader) (*Summary, error) {
+	return func(body io.Reader) (*Summary, error) {
+		if err := json.NewDecoder(body).Decode(v); err != nil {
+			return nil, err
+		}
+
+		return v.summary(), nil
+	}
+}
+
+/**
+ * Requests url and hands the response body to decode as it is read, so it
+ * never has to be held in memory whole. decode returns the response's
+ * SUMMARY and is called again from the start if the request is retried.
+ */
+func (c *Client) Stream(ctx context.Context, url string, decode func(body io.Reader) (*Summary, error)) error {
+	for attempt := 0; ; attempt++ {
+		summary, retryAfter, retry, err := c.get(ctx, url, decode)
+
+		if err == nil {
+			return summaryError(summary)
+		}
+
+		if !retry || attempt >= c.Retries || ctx.Err() != nil {
+			return err
+		}
+
+		wait := c.backoff(attempt)
+
+		if retryAfter > 0 {
+			if retryAfter > c.MaxBackoff {
+				return fmt.Errorf("%w, retry after %s", err, retryAfter)
+			}
+
+			wait = retryAfter
+		}
+
+		log.Printf("Synoptic request failed: %s. Retrying in %s", err, wait)
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+/**
+ * Makes a single attempt. Also returns whether the request is worth retrying
+ * and how long the server asked to wait before doing so.
+ */
+func (c *Client) get(ctx context.Context, url string, decode func(io.Reader) (*Summary, error)) (*Summary, time.Duration, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	httpClient := c.HTTPClient
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+
+	if err != nil {
+		return nil, 0, true, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		// Drain the body so the connection can be reused
+		io.Copy(io.Discard, res.Body)
+
+		err := &HTTPError{StatusCode: res.StatusCode, Status: res.Status}
+		retry := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+
+		return nil, retryAfter(res.Header.Get("Retry-After")), retry, err
+	}
+
+	summary, err := decode(res.Body)
+
+	if err != nil {
+		return nil, 0, truncated(err) || !invalidJSON(err), fmt.Errorf("synoptic: could not decode response: %w", err)
+	}
+
+	return summary, 0, false, nil
+}
+
+// A body cut short, by a dropped connection for instance, is worth retrying
+func truncated(err error) bool {
+	var syntaxErr *json.SyntaxError
+
+	if errors.As(err, &syntaxErr) && syntaxErr.Error() == "unexpected end of JSON input" {
+		return true
+	}
+
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+/**
+ * Whether a decode error came from the response itself rather than the
+ * connection it was read from, in which case retrying won't help
+ */
+func invalidJSON(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, errInvalidResponse)
+}
+
+// Full jitter: a random wait between zero and the exponential backoff
+func (c *Client) backoff(attempt int) time.Duration {
+	backoff := c.MinBackoff << attempt
+
+	if backoff > c.MaxBackoff || backoff <= 0 {
+		backoff = c.MaxBackoff
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+
+	return time.Duration(jitter.Int63n(int64(backoff)))
+}
+
+// Parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(header); err == nil {
+		return time.Until(at)
+	}
+
+	return 0
+}
@@ -0,0 +1,117 @@
+package synoptic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestClient() *Client {
+	client := NewClient()
+	client.MinBackoff = time.Millisecond
+	client.MaxBackoff = 10 * time.Millisecond
+
+	return client
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	attempts := 0
+
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		w.Write([]byte(TEST_DATA))
+	})
+
+	api.Client = newTestClient()
+
+	_, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestClientGivesUp(t *testing.T) {
+	attempts := 0
+
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	api.Client = newTestClient()
+
+	_, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	var httpErr *HTTPError
+
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected an HTTPError, got %v", err)
+	}
+
+	if attempts != api.Client.Retries+1 {
+		t.Errorf("Expected %d attempts, got %d", api.Client.Retries+1, attempts)
+	}
+}
+
+func TestClientRateLimited(t *testing.T) {
+	attempts := 0
+
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	api.Client = newTestClient()
+
+	_, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+
+	// An hour is longer than MaxBackoff so it is not waited out
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestClientResponseCodes(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected error
+	}{
+		{`{"SUMMARY": {"RESPONSE_CODE": 200, "RESPONSE_MESSAGE": "Invalid token."}}`, ErrInvalidToken},
+		{`{"SUMMARY": {"RESPONSE_CODE": 2, "RESPONSE_MESSAGE": "No stations found for this request."}}`, ErrNoStations},
+		{`{"SUMMARY": {"RESPONSE_CODE": 400, "RESPONSE_MESSAGE": "Account has exceeded its service unit limit."}}`, ErrRateLimited},
+		{`{"SUMMARY": {"RESPONSE_CODE": 400, "RESPONSE_MESSAGE": "Invalid stid."}}`, ErrInvalidRequest},
+		{`{"STATION": [{"STID": "KLNK", "STATUS": "INACTIVE"}], "SUMMARY": {"RESPONSE_CODE": 1}}`, ErrStationOutOfService},
+	}
+
+	for _, test := range tests {
+		api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(test.body))
+		})
+
+		api.Client = newTestClient()
+
+		_, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+		if !errors.Is(err, test.expected) {
+			t.Errorf("Expected %v for %s, got %v", test.expected, test.body, err)
+		}
+	}
+}
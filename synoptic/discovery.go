@@ -0,0 +1,169 @@
+package synoptic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var ErrNoStationsFound = errors.New("synoptic: no active air temperature stations found")
+
+// Resolves a free form place (ZIP code or city name) to a latitude and longitude
+type Geocoder interface {
+	Geocode(ctx context.Context, place string) (latitude float64, longitude float64, err error)
+}
+
+type Discovery struct {
+	// Root of the Synoptic v2 API. Defaults to SYNOPTIC_API_URL
+	BaseUrl *url.URL
+	// Used by FindStationsNear to turn a ZIP code or city into coordinates
+	Geocoder Geocoder
+	// How far from the search point to look for stations
+	RadiusMiles float64
+	// Maximum number of stations to ask Synoptic for
+	Limit int
+	// Stations that have not reported for longer than this are skipped
+	MaxStaleness time.Duration
+	// Makes the requests. Defaults to NewClient
+	Client *Client
+}
+
+func NewDiscovery(geocoder Geocoder) *Discovery {
+	return &Discovery{
+		BaseUrl:      SYNOPTIC_API_URL,
+		Geocoder:     geocoder,
+		RadiusMiles:  25,
+		Limit:        20,
+		MaxStaleness: time.Hour * 24 * 7,
+		Client:       NewClient(),
+	}
+}
+
+/**
+ * Returns nearby active stations that report air_temp, nearest first
+ * @see https://developers.synopticdata.com/mesonet/v2/stations/metadata/
+ */
+func (d *Discovery) FindStations(ctx context.Context, latitude float64, longitude float64) ([]*Station, error) {
+	url := d.BaseUrl.JoinPath("stations", "metadata")
+	query := url.Query()
+
+	query.Add("token", SYNOPTIC_API_TOKEN)
+	query.Add("radius", fmt.Sprintf("%f,%f,%g", latitude, longitude, d.RadiusMiles))
+	query.Add("vars", "air_temp")
+	// SENSOR_VARIABLES is left out of the response without it
+	query.Add("sensorvars", "1")
+	query.Add("status", "active")
+	query.Add("limit", strconv.Itoa(d.Limit))
+
+	url.RawQuery = query.Encode()
+
+	log.Printf("Searching for stations near %f,%f", latitude, longitude)
+
+	client := d.Client
+
+	if client == nil {
+		client = NewClient()
+	}
+
+	var metadataResponse SynopticMetadataResponse
+	err := client.Get(ctx, url.String(), &metadataResponse)
+
+	// Synoptic reports an empty search as an error
+	if errors.Is(err, ErrNoStations) {
+		return nil, ErrNoStationsFound
+	}
+
+	if err != nil {
+		log.Printf("Error making request: %s", err)
+		return nil, err
+	}
+
+	stations := d.rankStations(metadataResponse.Station, time.Now())
+
+	if len(stations) == 0 {
+		return nil, ErrNoStationsFound
+	}
+
+	return stations, nil
+}
+
+/**
+ * Geocodes a ZIP code or city name and returns the stations near it
+ */
+func (d *Discovery) FindStationsNear(ctx context.Context, place string) ([]*Station, error) {
+	if d.Geocoder == nil {
+		return nil, errors.New("synoptic: no geocoder configured for station discovery")
+	}
+
+	latitude, longitude, err := d.Geocoder.Geocode(ctx, place)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return d.FindStations(ctx, latitude, longitude)
+}
+
+/**
+ * Returns the best station for a ZIP code or city name
+ */
+func (d *Discovery) NearestStation(ctx context.Context, place string) (*Station, error) {
+	stations, err := d.FindStationsNear(ctx, place)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stations[0], nil
+}
+
+/**
+ * Drops stations that are inactive, do not report air_temp or have gone stale
+ * and orders the rest by distance
+ */
+func (d *Discovery) rankStations(stations []*Station, now time.Time) []*Station {
+	ranked := []*Station{}
+
+	for _, station := range stations {
+		if !station.active() {
+			continue
+		}
+
+		if station.SensorVariables == nil || len(station.SensorVariables.AirTemp) == 0 {
+			continue
+		}
+
+		if lastReport, ok := station.lastReport(); ok && now.Sub(lastReport) > d.MaxStaleness {
+			continue
+		}
+
+		ranked = append(ranked, station)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Distance < ranked[j].Distance
+	})
+
+	return ranked
+}
+
+func (s *Station) lastReport() (time.Time, bool) {
+	end, ok := s.PeriodOfRecord["end"].(string)
+
+	if !ok {
+		return time.Time{}, false
+	}
+
+	lastReport, err := time.Parse(time.RFC3339, end)
+
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return lastReport, true
+}
@@ -0,0 +1,130 @@
+package synoptic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const testMetadata = `
+{
+    "STATION": [
+        {
+            "STID": "FAR",
+            "NAME": "Far Away",
+            "STATUS": "Active",
+            "TIMEZONE": "America/Chicago",
+            "DISTANCE": 12.5,
+            "PERIOD_OF_RECORD": {"start": "2002-04-29T00:00:00Z", "end": "2023-01-11T19:55:00Z"},
+            "SENSOR_VARIABLES": {"air_temp": {"air_temp_set_1": {"position": "2.0"}}}
+        },
+        {
+            "STID": "NOTEMP",
+            "NAME": "No Thermometer",
+            "STATUS": "ACTIVE",
+            "TIMEZONE": "America/Chicago",
+            "DISTANCE": 0.5,
+            "PERIOD_OF_RECORD": {"start": "2002-04-29T00:00:00Z", "end": "2023-01-11T19:55:00Z"},
+            "SENSOR_VARIABLES": {"wind_speed": {"wind_speed_set_1": {}}}
+        },
+        {
+            "STID": "OLD",
+            "NAME": "Stale",
+            "STATUS": "ACTIVE",
+            "TIMEZONE": "America/Chicago",
+            "DISTANCE": 1.0,
+            "PERIOD_OF_RECORD": {"start": "2002-04-29T00:00:00Z", "end": "2019-01-01T00:00:00Z"},
+            "SENSOR_VARIABLES": {"air_temp": {"air_temp_set_1": {}}}
+        },
+        {
+            "STID": "KLNK",
+            "NAME": "Lincoln, Lincoln Municipal Airport",
+            "STATUS": "ACTIVE",
+            "TIMEZONE": "America/Chicago",
+            "DISTANCE": 3.2,
+            "PERIOD_OF_RECORD": {"start": "2002-04-29T00:00:00Z", "end": "2023-01-11T19:55:00Z"},
+            "SENSOR_VARIABLES": {"air_temp": {"air_temp_set_1": {"position": "2.0"}}}
+        },
+        {
+            "STID": "GONE",
+            "NAME": "Inactive",
+            "STATUS": "INACTIVE",
+            "DISTANCE": 0.1,
+            "SENSOR_VARIABLES": {"air_temp": {"air_temp_set_1": {}}}
+        }
+    ],
+    "SUMMARY": {"NUMBER_OF_OBJECTS": 5, "RESPONSE_CODE": 1, "RESPONSE_MESSAGE": "OK"}
+}
+`
+
+type fakeGeocoder struct{}
+
+func (g *fakeGeocoder) Geocode(ctx context.Context, place string) (float64, float64, error) {
+	return 40.8, -96.7, nil
+}
+
+func newTestDiscovery(t *testing.T, body string) *Discovery {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stations/metadata" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+
+		if radius := r.URL.Query().Get("radius"); radius != "40.800000,-96.700000,25" {
+			t.Errorf("Unexpected radius %s", radius)
+		}
+
+		if sensorvars := r.URL.Query().Get("sensorvars"); sensorvars != "1" {
+			t.Errorf("Expected sensorvars=1, got %q", sensorvars)
+		}
+
+		w.Write([]byte(body))
+	}))
+
+	t.Cleanup(server.Close)
+
+	baseUrl, _ := url.Parse(server.URL)
+
+	discovery := NewDiscovery(&fakeGeocoder{})
+	discovery.BaseUrl = baseUrl
+	// Fixture stations last reported in January 2023
+	discovery.MaxStaleness = time.Since(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	return discovery
+}
+
+func TestNearestStation(t *testing.T) {
+	discovery := newTestDiscovery(t, testMetadata)
+
+	stations, err := discovery.FindStationsNear(context.Background(), "68508")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stations) != 2 {
+		t.Fatalf("Expected 2 stations, got %d", len(stations))
+	}
+
+	if stations[0].Stid != "KLNK" || stations[1].Stid != "FAR" {
+		t.Errorf("Stations ranked incorrectly: %s, %s", stations[0].Stid, stations[1].Stid)
+	}
+
+	api := NewForStation(stations[0])
+
+	if api.StationId != "KLNK" || api.Location.String() != "America/Chicago" {
+		t.Errorf("Unexpected api for station %s %v", api.StationId, api.Location)
+	}
+}
+
+func TestNearestStationNoneFound(t *testing.T) {
+	discovery := newTestDiscovery(t, `{"STATION": [], "SUMMARY": {"RESPONSE_CODE": 2}}`)
+
+	_, err := discovery.NearestStation(context.Background(), "68508")
+
+	if err != ErrNoStationsFound {
+		t.Errorf("Expected ErrNoStationsFound, got %v", err)
+	}
+}
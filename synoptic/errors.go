@@ -0,0 +1,92 @@
+package synoptic
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var ErrNoStations = errors.New("synoptic: no stations returned")
+var ErrInvalidToken = errors.New("synoptic: invalid or missing token")
+var ErrRateLimited = errors.New("synoptic: rate limited")
+var ErrInvalidRequest = errors.New("synoptic: invalid request")
+var ErrStationOutOfService = errors.New("synoptic: station is out of service")
+
+// Returned by decoders for responses that are valid JSON but not the shape
+// expected. Like a syntax error, it is not worth retrying
+var errInvalidResponse = errors.New("synoptic: unexpected response")
+
+// SUMMARY.RESPONSE_CODE values
+// @see https://developers.synopticdata.com/mesonet/v2/api-variables/
+const (
+	responseOK             = 1
+	responseZeroResults    = 2
+	responseAuthentication = 200
+	responseRuleViolation  = 400
+)
+
+/**
+ * An error Synoptic reported in a response's SUMMARY. Err is one of the
+ * errors above when the code is a known one, so callers can use errors.Is.
+ */
+type APIError struct {
+	Code    int
+	Message string
+	Err     error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("synoptic: %s (code %d)", e.Message, e.Code)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// A request that still failed with a non-200 status after any retries
+type HTTPError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("synoptic: request failed with %s", e.Status)
+}
+
+func (e *HTTPError) Unwrap() error {
+	if e.StatusCode == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+
+	return nil
+}
+
+// Returns nil when the summary reports success
+func summaryError(summary *Summary) error {
+	if summary == nil || summary.ResponseCode == responseOK {
+		return nil
+	}
+
+	apiErr := &APIError{Code: summary.ResponseCode, Message: summary.ResponseMessage}
+
+	switch summary.ResponseCode {
+	case responseZeroResults:
+		apiErr.Err = ErrNoStations
+	case responseAuthentication:
+		apiErr.Err = ErrInvalidToken
+	case responseRuleViolation:
+		apiErr.Err = ErrInvalidRequest
+
+		if isRateLimit(summary.ResponseMessage) {
+			apiErr.Err = ErrRateLimited
+		}
+	}
+
+	return apiErr
+}
+
+// Synoptic reports exceeding an account's limits as a rule violation
+func isRateLimit(message string) bool {
+	return strings.Contains(strings.ToLower(message), "limit")
+}
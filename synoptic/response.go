@@ -1,15 +1,196 @@
 package synoptic
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
 
 type SynopticTimeSeriesResponse struct {
-	Units   *Units     `json:"UNITS"`
+	Units     *Units     `json:"UNITS"`
+	QcSummary *QcSummary `json:"QC_SUMMARY"`
+	Station   []*Station `json:"STATION"`
+	Summary   *Summary   `json:"SUMMARY"`
+}
+
+type SynopticMetadataResponse struct {
 	Station []*Station `json:"STATION"`
 	Summary *Summary   `json:"SUMMARY"`
 }
 
+type SynopticStatisticsResponse struct {
+	Units   *Units               `json:"UNITS"`
+	Station []*StatisticsStation `json:"STATION"`
+	Summary *Summary             `json:"SUMMARY"`
+}
+
+type StatisticsStation struct {
+	Status     string `json:"STATUS"`
+	Stid       string `json:"STID"`
+	Timezone   string `json:"TIMEZONE"`
+	Statistics *struct {
+		// One entry per period
+		AirTemp []*Statistic `json:"air_temp_set_1"`
+	} `json:"STATISTICS"`
+}
+
+// A variable's statistics over one period, ex: a day
+type Statistic struct {
+	PeriodOfRecord *struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"PERIOD_OF_RECORD"`
+	Count   int      `json:"count"`
+	Maximum *float64 `json:"maximum"`
+	MaxTime string   `json:"maxtime"`
+	Minimum *float64 `json:"minimum"`
+	MinTime string   `json:"mintime"`
+	Average *float64 `json:"average"`
+}
+
+func (r *SynopticStatisticsResponse) summary() *Summary {
+	return r.Summary
+}
+
+func (r *SynopticTimeSeriesResponse) summary() *Summary {
+	return r.Summary
+}
+
+func (r *SynopticMetadataResponse) summary() *Summary {
+	return r.Summary
+}
+
 type Units struct {
-	AirTemp string `json:"air_temp"`
+	AirTemp  string `json:"air_temp"`
+	DewPoint string `json:"dew_point_temperature"`
+	// Of sensor heights, m or ft
+	Position string `json:"position"`
+	// Of station elevations, m or ft
+	Elevation       string `json:"elevation"`
+	PrecipIntervals string `json:"precip_intervals"`
+	SnowInterval    string `json:"snow_interval"`
+	WindGust        string `json:"wind_gust"`
+}
+
+// The quality control Synoptic ran over the response's readings
+type QcSummary struct {
+	QcChecksApplied                   []string `json:"QC_CHECKS_APPLIED"`
+	TotalObservationsFlagged          float64  `json:"TOTAL_OBSERVATIONS_FLAGGED"`
+	PercentOfTotalObservationsFlagged float64  `json:"PERCENT_OF_TOTAL_OBSERVATIONS_FLAGGED"`
+}
+
+/**
+ * Units of the air temperature and dew point readings. Synoptic reports
+ * metric units unless asked for english ones, so celsius is assumed when the
+ * response doesn't say.
+ */
+func temperatureUnits(units *Units) (weather.Unit, weather.Unit, error) {
+	airTemp, dewPoint := "Celsius", ""
+
+	if units != nil && units.AirTemp != "" {
+		airTemp = units.AirTemp
+	}
+
+	if units != nil {
+		dewPoint = units.DewPoint
+	}
+
+	// Dew points are in the same units as the air temperature
+	if dewPoint == "" {
+		dewPoint = airTemp
+	}
+
+	airTempUnit, err := weather.ParseUnit(airTemp)
+
+	if err != nil {
+		return "", "", fmt.Errorf("synoptic: %w", err)
+	}
+
+	dewPointUnit, err := weather.ParseUnit(dewPoint)
+
+	if err != nil {
+		return "", "", fmt.Errorf("synoptic: %w", err)
+	}
+
+	return airTempUnit, dewPointUnit, nil
+}
+
+/**
+ * Converts the precipitation, snowfall and wind gust readings from the units
+ * Synoptic reported them in to inches and miles per hour. Like temperatures,
+ * metric is assumed when the response doesn't say.
+ */
+type conditionUnits struct {
+	precipitation func(float64) float64
+	snowfall      func(float64) float64
+	windGust      func(float64) float64
+}
+
+func newConditionUnits(units *Units) (*conditionUnits, error) {
+	if units == nil {
+		units = &Units{}
+	}
+
+	precipitation, err := toInches(units.PrecipIntervals)
+
+	if err != nil {
+		return nil, err
+	}
+
+	snowfall, err := toInches(units.SnowInterval)
+
+	if err != nil {
+		return nil, err
+	}
+
+	windGust, err := toMilesPerHour(units.WindGust)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &conditionUnits{
+		precipitation: precipitation,
+		snowfall:      snowfall,
+		windGust:      windGust,
+	}, nil
+}
+
+func toInches(unit string) (func(float64) float64, error) {
+	switch strings.ToLower(unit) {
+	case "", "millimeters", "mm":
+		return weather.MillimetersToInches, nil
+	case "centimeters", "cm":
+		return weather.CentimetersToInches, nil
+	case "inches", "in":
+		return unchanged, nil
+	default:
+		return nil, fmt.Errorf("synoptic: unknown length unit %q", unit)
+	}
+}
+
+func toMilesPerHour(unit string) (func(float64) float64, error) {
+	switch strings.ToLower(unit) {
+	case "", "m/s", "meters/second":
+		return weather.MetersPerSecondToMilesPerHour, nil
+	case "km/h", "kph", "kilometers/hour":
+		return weather.KilometersPerHourToMilesPerHour, nil
+	case "knots", "kts", "kt":
+		return weather.KnotsToMilesPerHour, nil
+	case "mph", "miles/hour":
+		return unchanged, nil
+	default:
+		return nil, fmt.Errorf("synoptic: unknown speed unit %q", unit)
+	}
+}
+
+func unchanged(value float64) float64 {
+	return value
 }
 
 type Station struct {
@@ -19,29 +200,119 @@ type Station struct {
 	Latitude       string                 `json:"LATITUDE"`
 	Timezone       string                 `json:"TIMEZONE"`
 	Id             string                 `json:"ID"`
+	Stid           string                 `json:"STID"`
 	State          string                 `json:"STATE"`
 	PeriodOfRecord map[string]interface{} `json:"PERIOD_OF_RECORD"`
 	// Try setting to int
-	Elevation       int32            `json:"ELEVATION,string"`
-	Name            string           `json:"NAME"`
+	Elevation int32 `json:"ELEVATION,string"`
+	// Elevation from a digital elevation model, for comparison with the
+	// reported one
+	ElevDem float64 `json:"ELEV_DEM,string"`
+	Name    string  `json:"NAME"`
+	// Data from restricted stations needs extra permissions
+	Restricted bool `json:"RESTRICTED"`
+	// Units of the station's position and elevation
+	Units           *Units           `json:"UNITS"`
 	QcFlagged       bool             `json:"QC_FLAGGED"`
 	SensorVariables *SensorVariables `json:"SENSOR_VARIABLES"`
 	Observations    *Observations    `json:"OBSERVATIONS"`
+	// The checks each reading failed keyed by set, null where it passed.
+	// Only present when the flags are asked for
+	QC map[string][]interface{} `json:"QC"`
+	// Miles from the search point. Only present on radius searches
+	Distance float64 `json:"DISTANCE"`
 }
 
 type SensorVariables struct {
 	DateTime map[string]interface{} `json:"date_time"`
-	AirTemp  map[string]interface{} `json:"air_temp"`
+	// Keyed by set, ex: air_temp_set_1
+	AirTemp map[string]*SensorSet `json:"air_temp"`
+}
+
+// One of a station's sensors for a variable
+type SensorSet struct {
+	// Height above the ground in UNITS.position. A string or a number
+	Position interface{} `json:"position"`
+	// The sets a derived set was computed from
+	DerivedFrom    []string          `json:"derived_from"`
+	PeriodOfRecord map[string]string `json:"PERIOD_OF_RECORD"`
+}
+
+// The sensor's height, false when it isn't known
+func (s *SensorSet) Height() (float64, bool) {
+	switch position := s.Position.(type) {
+	case float64:
+		return position, true
+	case string:
+		height, err := strconv.ParseFloat(position, 64)
+		return height, err == nil
+	default:
+		return 0, false
+	}
 }
 
 type Observations struct {
 	DateTime []time.Time `json:"date_time"`
-	AirTemp  []float64   `json:"air_temp_set_1"`
+	// Every air temperature set keyed by name, ex: air_temp_set_1 or the
+	// derived air_temp_set_1d. Null where a reading is missing or was
+	// removed by quality control
+	AirTemp map[string][]*float64 `json:"-"`
+	// The sensors below are left out of the response when a station doesn't
+	// have them. Precipitation during each interval is derived by Synoptic
+	// from the station's accumulation reports.
+	PrecipIntervals  []*float64 `json:"precip_intervals_set_1d"`
+	SnowInterval     []*float64 `json:"snow_interval_set_1"`
+	WindGust         []*float64 `json:"wind_gust_set_1"`
+	RelativeHumidity []*float64 `json:"relative_humidity_set_1"`
+	DewPoint         []*float64 `json:"dew_point_temperature_set_1"`
+	// Computed from temperature and humidity for stations that don't report
+	// a dew point
+	DewPointDerived []*float64 `json:"dew_point_temperature_set_1d"`
+}
+
+var airTempSet = regexp.MustCompile(`^air_temp_set_\d+d?$`)
+
+// Decodes the sensors above and collects every air temperature set
+func (o *Observations) UnmarshalJSON(data []byte) error {
+	type observations Observations
+
+	if err := json.Unmarshal(data, (*observations)(o)); err != nil {
+		return err
+	}
+
+	var sets map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &sets); err != nil {
+		return err
+	}
+
+	o.AirTemp = map[string][]*float64{}
+
+	for name, raw := range sets {
+		if !airTempSet.MatchString(name) {
+			continue
+		}
+
+		var values []*float64
+
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return fmt.Errorf("synoptic: invalid %s: %w", name, err)
+		}
+
+		o.AirTemp[name] = values
+	}
+
+	return nil
 }
 
 type Summary struct {
-	NumberOfObjects int    `json:"NUMBER_OF_OBJECTS"`
-	ResponseCode    int    `json:"RESPONSE_CODE"`
-	ResponseMessage string `json:"RESPONSE_MESSAGE"`
-	TotalDataTime   string `json:"TOTAL_DATA_TIME"`
+	NumberOfObjects      int    `json:"NUMBER_OF_OBJECTS"`
+	ResponseCode         int    `json:"RESPONSE_CODE"`
+	ResponseMessage      string `json:"RESPONSE_MESSAGE"`
+	TotalDataTime        string `json:"TOTAL_DATA_TIME"`
+	DataQueryTime        string `json:"DATA_QUERY_TIME"`
+	DataParsingTime      string `json:"DATA_PARSING_TIME"`
+	MetadataResponseTime string `json:"METADATA_RESPONSE_TIME"`
+	FunctionUsed         string `json:"FUNCTION_USED"`
+	Version              string `json:"VERSION"`
 }
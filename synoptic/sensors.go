@@ -0,0 +1,124 @@
+package synoptic
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Meters above the ground thermometers are meant to be screened at
+const standardHeight = 2.0
+
+const metersPerFoot = 0.3048
+
+/**
+ * Picks the air temperature set to use when a station has more than one.
+ * Sets without a usable reading are skipped. Of the rest, measured sets are
+ * preferred over derived ones, then the sensor closest to the standard
+ * screen height, then the one with the fewest readings missing or flagged by
+ * QC and finally the lowest numbered set. readings counts a set's usable
+ * readings and those that are missing or flagged. Returns an empty string
+ * when the station has no usable air temperature readings.
+ */
+func (station *Station) preferredSet(names []string, positionUnit string, readings func(name string) (usable int, bad int)) string {
+	usable := []string{}
+
+	for _, name := range names {
+		if count, _ := readings(name); count > 0 {
+			usable = append(usable, name)
+		}
+	}
+
+	if len(usable) == 0 {
+		return ""
+	}
+
+	badReadings := func(name string) int {
+		_, bad := readings(name)
+		return bad
+	}
+
+	sort.Slice(usable, func(i, j int) bool {
+		a, b := usable[i], usable[j]
+
+		if derivedSet(a) != derivedSet(b) {
+			return !derivedSet(a)
+		}
+
+		aOffset, aKnown := station.heightOffset(a, positionUnit)
+		bOffset, bKnown := station.heightOffset(b, positionUnit)
+
+		if aKnown != bKnown {
+			return aKnown
+		}
+
+		if aKnown && aOffset != bOffset {
+			return aOffset < bOffset
+		}
+
+		if aBad, bBad := badReadings(a), badReadings(b); aBad != bBad {
+			return aBad < bBad
+		}
+
+		return setNumber(a) < setNumber(b)
+	})
+
+	return usable[0]
+}
+
+// Meters the set's sensor is from the standard height
+func (station *Station) heightOffset(name string, positionUnit string) (float64, bool) {
+	if station.SensorVariables == nil || station.SensorVariables.AirTemp[name] == nil {
+		return 0, false
+	}
+
+	height, ok := station.SensorVariables.AirTemp[name].Height()
+
+	if !ok {
+		return 0, false
+	}
+
+	if positionUnit == "ft" {
+		height *= metersPerFoot
+	}
+
+	return math.Abs(height - standardHeight), true
+}
+
+// Readings that are present and were not flagged by QC
+func usableReadings(present int, flagged int) int {
+	if flagged > present {
+		return 0
+	}
+
+	return present - flagged
+}
+
+// Readings in the set that failed one of the QC checks flags were asked for
+func (station *Station) flaggedReadings(name string) int {
+	bad := 0
+
+	for _, flags := range station.QC[name] {
+		if flags != nil {
+			bad++
+		}
+	}
+
+	return bad
+}
+
+// Derived sets are computed by Synoptic rather than measured, ex: air_temp_set_1d
+func derivedSet(name string) bool {
+	return strings.HasSuffix(name, "d")
+}
+
+func setNumber(name string) int {
+	number, err := strconv.Atoi(strings.TrimSuffix(name[strings.LastIndex(name, "_")+1:], "d"))
+
+	if err != nil {
+		return math.MaxInt
+	}
+
+	return number
+}
@@ -0,0 +1,204 @@
+package synoptic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPreferredAirTempSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		station  string
+		unit     string
+		expected string
+	}{
+		{
+			"closest to screen height",
+			`{
+				"SENSOR_VARIABLES": {"air_temp": {"air_temp_set_1": {"position": "10.0"}, "air_temp_set_2": {"position": 2}, "air_temp_set_1d": {"derived_from": ["air_temp_set_1"]}}},
+				"OBSERVATIONS": {"air_temp_set_1": [1.0], "air_temp_set_2": [1.0], "air_temp_set_1d": [1.0]}
+			}`,
+			"m",
+			"air_temp_set_2",
+		},
+		{
+			"positions in feet",
+			`{
+				"SENSOR_VARIABLES": {"air_temp": {"air_temp_set_1": {"position": "2.0"}, "air_temp_set_2": {"position": "6.5"}}},
+				"OBSERVATIONS": {"air_temp_set_1": [1.0], "air_temp_set_2": [1.0]}
+			}`,
+			"ft",
+			"air_temp_set_2",
+		},
+		{
+			"fewest readings removed by QC",
+			`{
+				"SENSOR_VARIABLES": {"air_temp": {"air_temp_set_1": {"position": "2.0"}, "air_temp_set_2": {"position": "2.0"}}},
+				"OBSERVATIONS": {"air_temp_set_1": [null, 1.0], "air_temp_set_2": [1.0, 1.0]}
+			}`,
+			"m",
+			"air_temp_set_2",
+		},
+		{
+			"fewest readings flagged by QC",
+			`{
+				"OBSERVATIONS": {"air_temp_set_1": [1.0, 1.0], "air_temp_set_2": [1.0, 1.0]},
+				"QC": {"air_temp_set_1": [null, ["sl_range_check"]], "air_temp_set_2": [null, null]}
+			}`,
+			"m",
+			"air_temp_set_2",
+		},
+		{
+			"lowest numbered set",
+			`{"OBSERVATIONS": {"air_temp_set_10": [1.0], "air_temp_set_2": [1.0]}}`,
+			"m",
+			"air_temp_set_2",
+		},
+		{
+			"measured over derived",
+			`{"OBSERVATIONS": {"air_temp_set_1d": [1.0], "air_temp_set_3": [null, 1.0]}}`,
+			"m",
+			"air_temp_set_3",
+		},
+		{
+			"skips sets without readings",
+			`{
+				"SENSOR_VARIABLES": {"air_temp": {"air_temp_set_1": {"position": "2.0"}, "air_temp_set_2": {"position": "10.0"}}},
+				"OBSERVATIONS": {"air_temp_set_1": [null, null], "air_temp_set_2": [1.0, 1.0]}
+			}`,
+			"m",
+			"air_temp_set_2",
+		},
+		{
+			"skips sets with every reading flagged",
+			`{
+				"SENSOR_VARIABLES": {"air_temp": {"air_temp_set_1": {"position": "2.0"}, "air_temp_set_2": {"position": "10.0"}}},
+				"OBSERVATIONS": {"air_temp_set_1": [1.0], "air_temp_set_2": [1.0]},
+				"QC": {"air_temp_set_1": [["sl_range_check"]]}
+			}`,
+			"m",
+			"air_temp_set_2",
+		},
+	}
+
+	for _, test := range tests {
+		var station Station
+
+		if err := json.Unmarshal([]byte(test.station), &station); err != nil {
+			t.Fatal(err)
+		}
+
+		names := []string{}
+
+		for name := range station.Observations.AirTemp {
+			names = append(names, name)
+		}
+
+		if set := station.preferredSet(names, test.unit, observedReadings(&station)); set != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, set)
+		}
+	}
+}
+
+// Counts the readings decoded with the station, the way the stream does as it reads them
+func observedReadings(station *Station) func(name string) (int, int) {
+	return func(name string) (int, int) {
+		values := station.Observations.AirTemp[name]
+		flagged := station.flaggedReadings(name)
+		present := 0
+
+		for _, value := range values {
+			if value != nil {
+				present++
+			}
+		}
+
+		return usableReadings(present, flagged), len(values) - present + flagged
+	}
+}
+
+func TestAirTempSets(t *testing.T) {
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"STATION": [{
+				"STID": "KLNK",
+				"SENSOR_VARIABLES": {"air_temp": {"air_temp_set_1": {"position": "9.0"}, "air_temp_set_2": {"position": "2.0"}}},
+				"OBSERVATIONS": {
+					"date_time": ["2023-01-10T12:00:00Z", "2023-01-10T13:00:00Z"],
+					"air_temp_set_1": [10.0, 20.0],
+					"air_temp_set_2": [0.0, 10.0]
+				}
+			}]
+		}`))
+	})
+
+	info, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprintf("%.1f/%.1f", info.High, info.Low) != "50.0/32.0" {
+		t.Errorf("Expected the 2m sensor to be used, got %+v", info)
+	}
+}
+
+func TestAirTempSetsWithoutReadings(t *testing.T) {
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"STATION": [{
+				"STID": "KLNK",
+				"SENSOR_VARIABLES": {"air_temp": {"air_temp_set_1": {"position": "2.0"}, "air_temp_set_2": {"position": "10.0"}}},
+				"OBSERVATIONS": {
+					"date_time": ["2023-01-10T12:00:00Z", "2023-01-10T13:00:00Z"],
+					"air_temp_set_1": [null, null],
+					"air_temp_set_2": [0.0, 10.0]
+				}
+			}]
+		}`))
+	})
+
+	info, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprintf("%.1f/%.1f", info.High, info.Low) != "50.0/32.0" {
+		t.Errorf("Expected the 10m sensor to be used, got %+v", info)
+	}
+}
+
+func TestResponseModel(t *testing.T) {
+	var res SynopticTimeSeriesResponse
+
+	if err := json.Unmarshal([]byte(TEST_DATA), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	station := res.Station[0]
+
+	if station.ElevDem != 1145 || station.Restricted || station.Units.Position != "m" {
+		t.Errorf("Unexpected station %+v", station)
+	}
+
+	if height, ok := station.SensorVariables.AirTemp["air_temp_set_1"].Height(); !ok || height != 2 {
+		t.Errorf("Expected a 2m sensor, got %v %v", height, ok)
+	}
+
+	if len(station.Observations.AirTemp["air_temp_set_1"]) != len(station.Observations.DateTime) {
+		t.Errorf("Expected a reading for every time")
+	}
+
+	if res.QcSummary == nil || res.QcSummary.QcChecksApplied[0] != "sl_range_check" {
+		t.Errorf("Unexpected QC summary %+v", res.QcSummary)
+	}
+
+	if res.Summary.Version != "v2.17.0" {
+		t.Errorf("Unexpected summary %+v", res.Summary)
+	}
+}
@@ -0,0 +1,196 @@
+package synoptic
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+// How an api turns a station's readings into daily summaries
+type Strategy string
+
+const (
+	// Download every reading and summarize each day locally. Gives coverage,
+	// filtering and the other sensors' conditions
+	Timeseries Strategy = "timeseries"
+	// Ask Synoptic for each day's max, min and mean. A fraction of the
+	// payload, which adds up over long backfills, but only temperatures come
+	// back and the api's Filter is not applied. Days the statistics are
+	// missing fall back to Timeseries
+	Statistics Strategy = "statistics"
+)
+
+func ParseStrategy(strategy string) (Strategy, error) {
+	switch Strategy(strategy) {
+	case "", Timeseries:
+		return Timeseries, nil
+	case Statistics:
+		return Statistics, nil
+	default:
+		return "", fmt.Errorf("unknown synoptic strategy %q, expected timeseries or statistics", strategy)
+	}
+}
+
+const statisticsDateFormat = "2006-01-02"
+
+/**
+ * Makes request to get daily air temperature statistics
+ * @see https://developers.synopticdata.com/mesonet/v2/stations/statistics/
+ */
+func (s *SynopticApi) GetStatistics(ctx context.Context, start time.Time, end time.Time) (*SynopticStatisticsResponse, error) {
+	query := url.Values{}
+
+	log.Printf("Statistics: %v - %v", start, end)
+
+	query.Add("start", start.Format("200601021504"))
+	query.Add("end", end.Format("200601021504"))
+	query.Add("vars", "air_temp")
+	query.Add("type", "all")
+	query.Add("period", "day")
+	// Days are split at the station's midnight
+	query.Add("obtimezone", "local")
+
+	var statisticsResponse SynopticStatisticsResponse
+
+	if err := s.get(ctx, "statistics", query, &statisticsResponse); err != nil {
+		return nil, err
+	}
+
+	return &statisticsResponse, nil
+}
+
+/**
+ * Summaries for the days Synoptic has statistics for. Days are matched on
+ * the date of each period, so they only line up with days in the api's
+ * timezone when it is the station's own.
+ */
+func (s *SynopticApi) statisticsWindow(ctx context.Context, days []time.Time, tz *time.Location) ([]*weather.WeatherInfo, error) {
+	windowEnd := weather.DayOf(days[len(days)-1], tz).End
+
+	res, err := s.GetStatistics(ctx, days[0].UTC(), windowEnd.UTC())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Station) == 0 {
+		return nil, fmt.Errorf("%w for station %s", ErrNoStations, s.StationId)
+	}
+
+	station := res.Station[0]
+	periods := station.periods()
+
+	if len(periods) == 0 && !isActive(station.Status) {
+		return nil, fmt.Errorf("%w: %s is %s", ErrStationOutOfService, station.Stid, station.Status)
+	}
+
+	unit, _, err := temperatureUnits(res.Units)
+
+	if err != nil {
+		return nil, err
+	}
+
+	weatherInfos := []*weather.WeatherInfo{}
+
+	for _, day := range days {
+		statistic, ok := periods[day.Format(statisticsDateFormat)]
+
+		if !ok || statistic.Maximum == nil || statistic.Minimum == nil || statistic.Average == nil {
+			log.Printf("%s: no statistics for %s", s.Name(), day.Format("Jan 2 2006"))
+			continue
+		}
+
+		weatherInfos = append(weatherInfos, &weather.WeatherInfo{
+			Date:     day,
+			High:     unit.ToFahrenheit(*statistic.Maximum),
+			Low:      unit.ToFahrenheit(*statistic.Minimum),
+			Average:  unit.ToFahrenheit(*statistic.Average),
+			Provider: s.Name(),
+		})
+	}
+
+	return weatherInfos, nil
+}
+
+// The station's air temperature statistics keyed by the date of each period
+func (station *StatisticsStation) periods() map[string]*Statistic {
+	periods := map[string]*Statistic{}
+
+	if station.Statistics == nil {
+		return periods
+	}
+
+	for _, statistic := range station.Statistics.AirTemp {
+		if statistic == nil || statistic.PeriodOfRecord == nil || len(statistic.PeriodOfRecord.Start) < len(statisticsDateFormat) {
+			continue
+		}
+
+		periods[statistic.PeriodOfRecord.Start[:len(statisticsDateFormat)]] = statistic
+	}
+
+	return periods
+}
+
+/**
+ * Merges the two strategies' summaries for the days. Where both have a day
+ * they are compared and the timeseries summary is kept, since it has been
+ * through the api's Filter and carries the day's coverage and conditions.
+ */
+func (s *SynopticApi) crossCheck(days []time.Time, statistics []*weather.WeatherInfo, timeseries []*weather.WeatherInfo) []*weather.WeatherInfo {
+	byDate := func(weatherInfos []*weather.WeatherInfo) map[string]*weather.WeatherInfo {
+		dates := map[string]*weather.WeatherInfo{}
+
+		for _, weatherInfo := range weatherInfos {
+			dates[weatherInfo.Date.Format(statisticsDateFormat)] = weatherInfo
+		}
+
+		return dates
+	}
+
+	fromStatistics := byDate(statistics)
+	fromTimeseries := byDate(timeseries)
+	weatherInfos := []*weather.WeatherInfo{}
+
+	for _, day := range days {
+		date := day.Format(statisticsDateFormat)
+		statistic, hasStatistic := fromStatistics[date]
+		summary, hasSummary := fromTimeseries[date]
+
+		switch {
+		case hasStatistic && hasSummary:
+			s.compare(statistic, summary)
+			weatherInfos = append(weatherInfos, summary)
+		case hasSummary:
+			weatherInfos = append(weatherInfos, summary)
+		case hasStatistic:
+			weatherInfos = append(weatherInfos, statistic)
+		}
+	}
+
+	return weatherInfos
+}
+
+// Logs when the statistics and the timeseries summary of a day disagree
+func (s *SynopticApi) compare(statistic *weather.WeatherInfo, summary *weather.WeatherInfo) {
+	differs := func(a float64, b float64) bool {
+		return math.Abs(a-b) > s.CrossCheckTolerance
+	}
+
+	if !differs(statistic.High, summary.High) && !differs(statistic.Low, summary.Low) && !differs(statistic.Average, summary.Average) {
+		return
+	}
+
+	log.Printf(
+		"%s: statistics and timeseries disagree for %s: high %.1f/%.1f, low %.1f/%.1f, average %.1f/%.1f",
+		s.Name(),
+		summary.Date.Format("Jan 2 2006"),
+		statistic.High, summary.High,
+		statistic.Low, summary.Low,
+		statistic.Average, summary.Average,
+	)
+}
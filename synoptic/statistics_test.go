@@ -0,0 +1,130 @@
+package synoptic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testStatistics = `{
+	"UNITS": {"air_temp": "Celsius"},
+	"STATION": [{
+		"STID": "KLNK",
+		"STATUS": "ACTIVE",
+		"STATISTICS": {
+			"air_temp_set_1": [
+				{"PERIOD_OF_RECORD": {"start": "2023-01-09T00:00:00-0600", "end": "2023-01-09T23:55:00-0600"}, "count": 288, "maximum": 5.0, "minimum": -5.0, "average": 0.0},
+				{"PERIOD_OF_RECORD": {"start": "2023-01-10T00:00:00-0600", "end": "2023-01-10T23:55:00-0600"}, "count": 288, "maximum": 13.0, "minimum": -5.0, "average": 3.0}
+			]
+		}
+	}],
+	"SUMMARY": {"RESPONSE_CODE": 1}
+}`
+
+func newStatisticsTestApi(t *testing.T, statistics string, requests *[]string) *SynopticApi {
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		endpoint := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		*requests = append(*requests, endpoint)
+
+		if endpoint == "statistics" {
+			w.Write([]byte(statistics))
+			return
+		}
+
+		w.Write([]byte(TEST_DATA))
+	})
+
+	api.Strategy = Statistics
+
+	return api
+}
+
+func TestStatistics(t *testing.T) {
+	requests := []string{}
+	api := newStatisticsTestApi(t, testStatistics, &requests)
+
+	weatherInfos, err := api.GetDailyRange(
+		context.Background(),
+		time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(requests) != "[statistics]" {
+		t.Errorf("Expected only a statistics request, got %v", requests)
+	}
+
+	if len(weatherInfos) != 2 {
+		t.Fatalf("Expected 2 days, got %d", len(weatherInfos))
+	}
+
+	info := weatherInfos[1]
+
+	if info.Date.Format("2006-01-02") != "2023-01-10" || fmt.Sprintf("%.1f/%.1f/%.1f", info.High, info.Low, info.Average) != "55.4/23.0/37.4" {
+		t.Errorf("Unexpected weather %+v", info)
+	}
+}
+
+func TestStatisticsFallback(t *testing.T) {
+	requests := []string{}
+	api := newStatisticsTestApi(t, `{"SUMMARY": {"RESPONSE_CODE": 400, "RESPONSE_MESSAGE": "Invalid period."}}`, &requests)
+
+	info, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(requests) != "[statistics timeseries]" {
+		t.Errorf("Expected statistics then timeseries requests, got %v", requests)
+	}
+
+	if info.Coverage == nil {
+		t.Errorf("Expected the day to be summarized from the timeseries, got %+v", info)
+	}
+}
+
+func TestStatisticsCrossCheck(t *testing.T) {
+	requests := []string{}
+	api := newStatisticsTestApi(t, testStatistics, &requests)
+	api.CrossCheck = true
+
+	weatherInfos, err := api.GetDailyRange(
+		context.Background(),
+		time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 11, 0, 0, 0, 0, time.UTC),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(requests) != "[statistics timeseries]" {
+		t.Errorf("Expected statistics and timeseries requests, got %v", requests)
+	}
+
+	// The 9th only has statistics, the 10th has both and the 11th neither
+	if len(weatherInfos) != 2 {
+		t.Fatalf("Expected 2 days, got %d", len(weatherInfos))
+	}
+
+	if weatherInfos[0].Coverage != nil || weatherInfos[1].Coverage == nil {
+		t.Errorf("Expected the timeseries summary to be kept when both are available")
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	if strategy, err := ParseStrategy(""); err != nil || strategy != Timeseries {
+		t.Errorf("Expected timeseries by default, got %v %v", strategy, err)
+	}
+
+	if _, err := ParseStrategy("hourly"); err == nil {
+		t.Error("Expected an error for an unknown strategy")
+	}
+}
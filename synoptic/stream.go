@@ -0,0 +1,494 @@
+package synoptic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/colevoss/temperature-blanket/weather"
+)
+
+// Sets the conditions are read from. See Observations
+const (
+	precipSet          = "precip_intervals_set_1d"
+	snowSet            = "snow_interval_set_1"
+	windGustSet        = "wind_gust_set_1"
+	humiditySet        = "relative_humidity_set_1"
+	dewPointSet        = "dew_point_temperature_set_1"
+	dewPointDerivedSet = "dew_point_temperature_set_1d"
+)
+
+/**
+ * Decodes a timeseries response as it is read, so the raw body is never held
+ * in memory whole. Each air temperature reading is put in the day it was
+ * taken and the other sensors are only kept as running totals per day, but
+ * the air temperatures and the times of every reading are still kept for the
+ * whole window, so memory grows with the window's length. GetDailyRange keeps
+ * that in check with MaxRequestDays. Only the first station is read.
+ */
+type timeseriesStream struct {
+	days      []time.Time
+	units     *Units
+	qcSummary *QcSummary
+	summary   *Summary
+	// The first station, without its observations
+	station   *Station
+	dateTimes []time.Time
+	// Index into days of each reading, -1 when it is outside of them
+	dayOf []int
+	byDay []*dayReadings
+	// Every air temperature set, with the times of its missing readings
+	airTempSets map[string][]time.Time
+	// Sets the station reported, even if every reading was missing
+	reported map[string]bool
+	// Sets that came before date_time, held until it has been read
+	pending map[string][]*float64
+}
+
+// One day's readings in the units Synoptic reported them in
+type dayReadings struct {
+	// Keyed by set
+	airTemp         map[string][]weather.Observation
+	conditions      weather.ConditionsTotals
+	derivedDewPoint weather.ConditionsTotals
+}
+
+func newTimeseriesStream(days []time.Time) *timeseriesStream {
+	byDay := make([]*dayReadings, len(days))
+
+	for i := range byDay {
+		byDay[i] = &dayReadings{airTemp: map[string][]weather.Observation{}}
+	}
+
+	return &timeseriesStream{
+		days:        days,
+		byDay:       byDay,
+		airTempSets: map[string][]time.Time{},
+		reported:    map[string]bool{},
+		pending:     map[string][]*float64{},
+	}
+}
+
+func (t *timeseriesStream) decode(body io.Reader) (*Summary, error) {
+	dec := json.NewDecoder(body)
+
+	err := eachKey(dec, func(key string) error {
+		switch key {
+		case "UNITS":
+			return dec.Decode(&t.units)
+		case "QC_SUMMARY":
+			return dec.Decode(&t.qcSummary)
+		case "SUMMARY":
+			return dec.Decode(&t.summary)
+		case "STATION":
+			return t.decodeStations(dec)
+		default:
+			return skip(dec)
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return t.summary, nil
+}
+
+func (t *timeseriesStream) decodeStations(dec *json.Decoder) error {
+	if err := expect(dec, '['); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		if t.station != nil {
+			if err := skip(dec); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := t.decodeStation(dec); err != nil {
+			return err
+		}
+	}
+
+	return expect(dec, ']')
+}
+
+// Everything but the observations is small, so it is decoded as usual
+func (t *timeseriesStream) decodeStation(dec *json.Decoder) error {
+	fields := map[string]json.RawMessage{}
+
+	err := eachKey(dec, func(key string) error {
+		if key == "OBSERVATIONS" {
+			return t.decodeObservations(dec)
+		}
+
+		var field json.RawMessage
+
+		if err := dec.Decode(&field); err != nil {
+			return err
+		}
+
+		fields[key] = field
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(fields)
+
+	if err != nil {
+		return err
+	}
+
+	t.station = &Station{}
+
+	return json.Unmarshal(metadata, t.station)
+}
+
+func (t *timeseriesStream) decodeObservations(dec *json.Decoder) error {
+	err := eachKey(dec, func(key string) error {
+		if key == "date_time" {
+			return t.decodeDateTimes(dec)
+		}
+
+		if !t.wanted(key) {
+			return skip(dec)
+		}
+
+		t.reported[key] = true
+
+		if t.dateTimes == nil {
+			return eachValue(dec, func(i int, value *float64) {
+				t.pending[key] = append(t.pending[key], value)
+			})
+		}
+
+		return eachValue(dec, func(i int, value *float64) {
+			t.add(key, i, value)
+		})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for key, values := range t.pending {
+		for i, value := range values {
+			t.add(key, i, value)
+		}
+
+		delete(t.pending, key)
+	}
+
+	return nil
+}
+
+func (t *timeseriesStream) decodeDateTimes(dec *json.Decoder) error {
+	if err := expect(dec, '['); err != nil {
+		return err
+	}
+
+	t.dateTimes = []time.Time{}
+
+	for dec.More() {
+		var observedAt time.Time
+
+		if err := dec.Decode(&observedAt); err != nil {
+			return err
+		}
+
+		t.dateTimes = append(t.dateTimes, observedAt)
+		t.dayOf = append(t.dayOf, t.day(observedAt))
+	}
+
+	return expect(dec, ']')
+}
+
+// Index of the day the time falls in, -1 when it is outside of the days
+func (t *timeseriesStream) day(observedAt time.Time) int {
+	i := sort.Search(len(t.days), func(i int) bool {
+		return t.days[i].After(observedAt)
+	}) - 1
+
+	if i < 0 || !weather.DayOf(t.days[i], t.days[i].Location()).Contains(observedAt) {
+		return -1
+	}
+
+	return i
+}
+
+func (t *timeseriesStream) wanted(key string) bool {
+	switch key {
+	case precipSet, snowSet, windGustSet, humiditySet, dewPointSet, dewPointDerivedSet:
+		return true
+	default:
+		return airTempSet.MatchString(key)
+	}
+}
+
+// Puts the i'th reading of a set in its day
+func (t *timeseriesStream) add(key string, i int, value *float64) {
+	if i >= len(t.dateTimes) || t.dayOf[i] < 0 {
+		return
+	}
+
+	observedAt := t.dateTimes[i]
+	day := t.byDay[t.dayOf[i]]
+
+	switch key {
+	case precipSet:
+		day.conditions.Add(weather.Conditions{Precipitation: value})
+	case snowSet:
+		day.conditions.Add(weather.Conditions{Snowfall: value})
+	case windGustSet:
+		day.conditions.Add(weather.Conditions{WindGust: value})
+	case humiditySet:
+		day.conditions.Add(weather.Conditions{Humidity: value})
+	case dewPointSet:
+		day.conditions.Add(weather.Conditions{DewPoint: value})
+	case dewPointDerivedSet:
+		day.derivedDewPoint.Add(weather.Conditions{DewPoint: value})
+	default:
+		if value == nil {
+			t.airTempSets[key] = append(t.airTempSets[key], observedAt)
+			return
+		}
+
+		if _, ok := t.airTempSets[key]; !ok {
+			t.airTempSets[key] = []time.Time{}
+		}
+
+		day.airTemp[key] = append(day.airTemp[key], weather.Observation{
+			Time:        observedAt,
+			Temperature: *value,
+		})
+	}
+}
+
+/**
+ * Summarizes the days the stream read from the station's preferred air
+ * temperature set, converted from the units Synoptic reported them in
+ */
+func (s *SynopticApi) summarize(t *timeseriesStream) ([]*weather.WeatherInfo, error) {
+	if t.station == nil {
+		return nil, fmt.Errorf("%w for station %s", ErrNoStations, s.StationId)
+	}
+
+	station := t.station
+
+	if !station.active() && len(t.dateTimes) == 0 {
+		return nil, fmt.Errorf("%w: %s is %s", ErrStationOutOfService, station.Stid, station.Status)
+	}
+
+	airTempUnit, dewPointUnit, err := temperatureUnits(t.units)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conditionUnits, err := newConditionUnits(t.units)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if t.qcSummary != nil && t.qcSummary.TotalObservationsFlagged > 0 {
+		log.Printf(
+			"%s: Synoptic QC flagged %.0f readings (%.1f%%) with %s",
+			s.Name(),
+			t.qcSummary.TotalObservationsFlagged,
+			t.qcSummary.PercentOfTotalObservationsFlagged,
+			strings.Join(t.qcSummary.QcChecksApplied, ", "),
+		)
+	}
+
+	if station.QcFlagged {
+		log.Printf("%s: Synoptic QC flagged readings for %s", s.Name(), station.Stid)
+	}
+
+	names := []string{}
+
+	for name := range t.airTempSets {
+		names = append(names, name)
+	}
+
+	set := station.preferredSet(names, positionUnit(station, t.units), func(name string) (int, int) {
+		present := 0
+
+		for _, day := range t.byDay {
+			present += len(day.airTemp[name])
+		}
+
+		flagged := station.flaggedReadings(name)
+
+		return usableReadings(present, flagged), len(t.airTempSets[name]) + flagged
+	})
+
+	if len(names) > 1 {
+		log.Printf("%s: using %s of %d air temperature sets", s.Name(), set, len(names))
+	}
+
+	observations := []weather.Observation{}
+
+	for _, day := range t.byDay {
+		for _, observation := range day.airTemp[set] {
+			observation.Temperature = airTempUnit.ToFahrenheit(observation.Temperature)
+			observations = append(observations, observation)
+		}
+	}
+
+	weatherInfos := weather.SummarizeDays(t.days, s.filter(observations, t.airTempSets[set]))
+
+	for _, weatherInfo := range weatherInfos {
+		day := t.byDay[t.day(weatherInfo.Date)]
+		day.fill(weatherInfo, conditionUnits, dewPointUnit, t.reported[dewPointSet])
+		weatherInfo.Provider = s.Name()
+	}
+
+	return weatherInfos, nil
+}
+
+/**
+ * Sets the day's conditions converted to imperial units. The derived dew
+ * point is only used for stations that don't report one.
+ */
+func (d *dayReadings) fill(weatherInfo *weather.WeatherInfo, units *conditionUnits, dewPointUnit weather.Unit, measuredDewPoint bool) {
+	d.conditions.Fill(weatherInfo)
+
+	if !measuredDewPoint {
+		derived := &weather.WeatherInfo{}
+		d.derivedDewPoint.Fill(derived)
+		weatherInfo.DewPoint = derived.DewPoint
+	}
+
+	weatherInfo.Precipitation = converted(weatherInfo.Precipitation, units.precipitation)
+	weatherInfo.Snowfall = converted(weatherInfo.Snowfall, units.snowfall)
+	weatherInfo.WindGust = converted(weatherInfo.WindGust, units.windGust)
+	weatherInfo.DewPoint = converted(weatherInfo.DewPoint, dewPointUnit.ToFahrenheit)
+}
+
+// Nil when the value is missing
+func converted(value *float64, convert func(float64) float64) *float64 {
+	if value == nil {
+		return nil
+	}
+
+	result := convert(*value)
+
+	return &result
+}
+
+// The station's own units are used over the response's
+func positionUnit(station *Station, units *Units) string {
+	if station.Units != nil && station.Units.Position != "" {
+		return station.Units.Position
+	}
+
+	if units != nil && units.Position != "" {
+		return units.Position
+	}
+
+	return "m"
+}
+
+// Calls fn with each key of the object at the decoder, which must read its value
+func eachKey(dec *json.Decoder, fn func(key string) error) error {
+	if err := expect(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+
+		if err != nil {
+			return err
+		}
+
+		key, ok := token.(string)
+
+		if !ok {
+			return fmt.Errorf("%w: object key %v", errInvalidResponse, token)
+		}
+
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+
+	return expect(dec, '}')
+}
+
+// Calls fn with each number of the array at the decoder, nil for anything else
+func eachValue(dec *json.Decoder, fn func(i int, value *float64)) error {
+	if err := expect(dec, '['); err != nil {
+		return err
+	}
+
+	for i := 0; dec.More(); i++ {
+		token, err := dec.Token()
+
+		if err != nil {
+			return err
+		}
+
+		if _, ok := token.(json.Delim); ok {
+			return fmt.Errorf("%w: nested value in a set", errInvalidResponse)
+		}
+
+		if value, ok := token.(float64); ok {
+			fn(i, &value)
+		} else {
+			fn(i, nil)
+		}
+	}
+
+	return expect(dec, ']')
+}
+
+func expect(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("%w: expected %s, got %v", errInvalidResponse, delim, token)
+	}
+
+	return nil
+}
+
+// Reads past the value at the decoder without keeping it
+func skip(dec *json.Decoder) error {
+	depth := 0
+
+	for {
+		token, err := dec.Token()
+
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package synoptic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamSetsBeforeDateTime(t *testing.T) {
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"SUMMARY": {"RESPONSE_CODE": 1},
+			"STATION": [{
+				"OBSERVATIONS": {
+					"air_temp_set_1": [0.0, 10.0, null],
+					"wind_gust_set_1": [5.0, 10.0, null],
+					"date_time": ["2023-01-10T12:00:00Z", "2023-01-10T13:00:00Z", "2023-01-10T14:00:00Z"],
+					"visibility_set_1": [[1], {"a": 1}]
+				},
+				"STID": "KLNK"
+			}, {
+				"STID": "OTHER",
+				"OBSERVATIONS": {"date_time": ["2023-01-10T12:00:00Z"], "air_temp_set_1": [100.0]}
+			}],
+			"UNITS": {"air_temp": "Celsius"}
+		}`))
+	})
+
+	info, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprintf("%.1f/%.1f/%.1f", info.High, info.Low, *info.WindGust) != "50.0/32.0/22.4" {
+		t.Errorf("Unexpected weather %+v", info)
+	}
+}
+
+// Writes a month of 5 minute readings a day at a time
+func TestStreamLargeResponse(t *testing.T) {
+	start := time.Date(2023, 1, 1, 6, 0, 0, 0, time.UTC)
+	readings := 31 * 24 * 12
+
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+
+		dateTimes := []string{}
+		temps := []string{}
+
+		for i := 0; i < readings; i++ {
+			dateTimes = append(dateTimes, fmt.Sprintf("%q", start.Add(time.Duration(i)*5*time.Minute).Format(time.RFC3339)))
+			temps = append(temps, fmt.Sprintf("%d.0", i%288/12))
+		}
+
+		fmt.Fprint(w, `{"STATION": [{"STID": "KLNK", "OBSERVATIONS": {"date_time": [`)
+
+		for i := 0; i < readings; i += 288 {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+
+			fmt.Fprint(w, strings.Join(dateTimes[i:i+288], ","))
+			flusher.Flush()
+		}
+
+		fmt.Fprintf(w, `], "air_temp_set_1": [%s]}}], "SUMMARY": {"RESPONSE_CODE": 1}}`, strings.Join(temps, ","))
+	})
+
+	weatherInfos, err := api.GetDailyRange(
+		context.Background(),
+		time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(weatherInfos) != 31 {
+		t.Fatalf("Expected 31 days, got %d", len(weatherInfos))
+	}
+
+	for _, info := range weatherInfos {
+		if info.Coverage.Observations != 288 || fmt.Sprintf("%.1f/%.1f", info.High, info.Low) != "73.4/32.0" {
+			t.Errorf("Unexpected weather for %s: %+v %+v", info.Date, info, info.Coverage)
+		}
+	}
+}
+
+func TestStreamRetriesTruncatedResponse(t *testing.T) {
+	attempts := 0
+
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			w.Write([]byte(TEST_DATA[:len(TEST_DATA)/2]))
+			return
+		}
+
+		w.Write([]byte(TEST_DATA))
+	})
+
+	api.Client = newTestClient()
+
+	if _, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("Expected the truncated response to be retried, got %d attempts", attempts)
+	}
+}
+
+func TestStreamInvalidResponse(t *testing.T) {
+	attempts := 0
+
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Write([]byte(`{"STATION": [{"OBSERVATIONS": {"date_time": "soon"}}]}`))
+	})
+
+	api.Client = newTestClient()
+
+	if _, err := api.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	if attempts != 1 {
+		t.Errorf("Expected an invalid response not to be retried, got %d attempts", attempts)
+	}
+}
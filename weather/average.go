@@ -0,0 +1,83 @@
+package weather
+
+import (
+	"fmt"
+	"sort"
+)
+
+// How a day's average temperature is defined
+type AverageMethod string
+
+const (
+	// Mean of every reading. Days with bursts of extra readings, like SPECIs
+	// during a storm, lean towards the temperatures during the burst
+	SampleMean AverageMethod = "mean"
+	// Mean weighted by how long each temperature lasted
+	TimeWeighted AverageMethod = "time-weighted"
+	// (High+Low)/2, the definition the National Weather Service uses
+	HighLowMean AverageMethod = "nws"
+)
+
+func ParseAverageMethod(method string) (AverageMethod, error) {
+	switch AverageMethod(method) {
+	case "", SampleMean:
+		return SampleMean, nil
+	case TimeWeighted:
+		return TimeWeighted, nil
+	case HighLowMean:
+		return HighLowMean, nil
+	default:
+		return "", fmt.Errorf("unknown average %q, expected mean, time-weighted or nws", method)
+	}
+}
+
+/**
+ * Returns the day's average by the given definition. Providers that only
+ * report a single daily average use it for every definition except nws.
+ */
+func (w *WeatherInfo) AverageFor(method AverageMethod) float64 {
+	if method == HighLowMean {
+		return (w.High + w.Low) / 2
+	}
+
+	if average, ok := w.Averages[method]; ok {
+		return average
+	}
+
+	return w.Average
+}
+
+/**
+ * Integrates the readings over time with the trapezoid rule, so each reading
+ * counts for as long as it lasted rather than once
+ */
+func TimeWeightedMean(observations []Observation) float64 {
+	sorted := make([]Observation, len(observations))
+	copy(sorted, observations)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	area := 0.0
+	duration := 0.0
+
+	for i := 1; i < len(sorted); i++ {
+		seconds := sorted[i].Time.Sub(sorted[i-1].Time).Seconds()
+		area += seconds * (sorted[i].Temperature + sorted[i-1].Temperature) / 2
+		duration += seconds
+	}
+
+	// A single reading, or readings all at the same time
+	if duration == 0 {
+		total := 0.0
+
+		for _, observation := range sorted {
+			total += observation.Temperature
+		}
+
+		return total / float64(len(sorted))
+	}
+
+	return area / duration
+}
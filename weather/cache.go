@@ -0,0 +1,271 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const cacheDateFormat = "2006-01-02"
+
+/**
+ * Implemented by providers whose name doesn't identify their data, ex: two
+ * Open-Meteo archives for different coordinates, so caches shared between
+ * blankets keep them apart
+ */
+type Keyed interface {
+	CacheKey() string
+}
+
+// Identifies w's data in a cache. Defaults to its name
+func ProviderKey(w Weather) string {
+	if keyed, ok := w.(Keyed); ok {
+		return keyed.CacheKey()
+	}
+
+	return ProviderName(w)
+}
+
+// Storage for finalized daily summaries
+type CacheStore interface {
+	// Returns nil without an error when nothing is cached for the day
+	Get(source string, date string) (*WeatherInfo, error)
+	Put(source string, date string, weatherInfo *WeatherInfo) error
+}
+
+/**
+ * A Weather that serves days it has already summarized from a CacheStore and
+ * only asks the wrapped provider for days it has not seen. Days that are not
+ * over yet are never cached, nor are days a Failover answered from one of its
+ * fallbacks so the primary is asked for them again.
+ */
+type Cache struct {
+	Weather Weather
+	Store   CacheStore
+	// Identifies the provider and station in the store, the timezone days
+	// are in is added to it. Defaults to the provider's ProviderKey
+	Source string
+	// Timezone the provider's days are in. When nil the provider's own is
+	// used if it has one, otherwise UTC
+	Location *time.Location
+	// How long after a day ends before it is considered final. Stations can
+	// report late observations.
+	Settle time.Duration
+	// Days that do not meet these are not cached so they are fetched again
+	// once more observations have come in
+	Coverage CoverageThresholds
+}
+
+func NewCache(weather Weather, store CacheStore) *Cache {
+	return &Cache{
+		Weather:  weather,
+		Store:    store,
+		Source:   ProviderKey(weather),
+		Settle:   time.Hour,
+		Coverage: DefaultCoverageThresholds,
+	}
+}
+
+func (c *Cache) Name() string {
+	return ProviderName(c.Weather)
+}
+
+func (c *Cache) GetDailyWeather(ctx context.Context, date time.Time) (*WeatherInfo, error) {
+	source := c.source(ctx)
+	key := date.Format(cacheDateFormat)
+
+	cached, err := c.Store.Get(source, key)
+
+	if err != nil {
+		log.Printf("Could not read cached weather for %s %s: %s", source, key, err)
+	}
+
+	if cached != nil {
+		log.Printf("Using cached weather for %s %s", source, key)
+		return cached, nil
+	}
+
+	weatherInfo, err := c.Weather.GetDailyWeather(ctx, date)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c.isFinal(weatherInfo, time.Now()) {
+		err = c.Store.Put(source, weatherInfo.Date.Format(cacheDateFormat), weatherInfo)
+
+		if err != nil {
+			log.Printf("Could not cache weather for %s %s: %s", source, key, err)
+		}
+	}
+
+	return weatherInfo, nil
+}
+
+/**
+ * Serves the cached days in the range and fetches the rest from the wrapped
+ * provider in a single range request spanning the missing days
+ */
+func (c *Cache) GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*WeatherInfo, error) {
+	tz, _ := c.GetLocation(ctx)
+	source := c.source(ctx)
+	days := Days(from, to, tz)
+	byDate := map[string]*WeatherInfo{}
+	missing := []time.Time{}
+
+	for _, day := range days {
+		date := day.Format(cacheDateFormat)
+		cached, err := c.Store.Get(source, date)
+
+		if err != nil {
+			log.Printf("Could not read cached weather for %s %s: %s", source, date, err)
+		}
+
+		if cached != nil {
+			byDate[date] = cached
+		} else {
+			missing = append(missing, day)
+		}
+	}
+
+	log.Printf("%d of %d days cached for %s", len(days)-len(missing), len(days), source)
+
+	if len(missing) > 0 {
+		fetched, err := c.Weather.GetDailyRange(ctx, missing[0], missing[len(missing)-1])
+
+		if err != nil && len(byDate) == 0 {
+			return nil, err
+		}
+
+		now := time.Now()
+
+		for _, weatherInfo := range fetched {
+			date := weatherInfo.Date.Format(cacheDateFormat)
+
+			if _, cached := byDate[date]; cached {
+				continue
+			}
+
+			byDate[date] = weatherInfo
+
+			if c.isFinal(weatherInfo, now) {
+				if err := c.Store.Put(source, date, weatherInfo); err != nil {
+					log.Printf("Could not cache weather for %s %s: %s", source, date, err)
+				}
+			}
+		}
+	}
+
+	weatherInfos := []*WeatherInfo{}
+
+	for _, day := range days {
+		if weatherInfo, ok := byDate[day.Format(cacheDateFormat)]; ok {
+			weatherInfos = append(weatherInfos, weatherInfo)
+		}
+	}
+
+	if len(weatherInfos) == 0 {
+		return nil, ErrNoDays
+	}
+
+	return weatherInfos, nil
+}
+
+// A day is final once it has ended, had time for late observations to arrive
+// and is complete. Days a fallback answered are never final.
+func (c *Cache) isFinal(weatherInfo *WeatherInfo, now time.Time) bool {
+	if failover, ok := c.Weather.(*Failover); ok && !failover.fromPrimary(weatherInfo) {
+		return false
+	}
+
+	end := DayOf(weatherInfo.Date, weatherInfo.Date.Location()).End
+
+	return now.After(end.Add(c.Settle)) && len(c.Coverage.Check(weatherInfo)) == 0
+}
+
+// The same day in two timezones covers different hours, so each is kept apart
+func (c *Cache) source(ctx context.Context) string {
+	tz, _ := c.GetLocation(ctx)
+
+	if tz == nil {
+		tz = time.UTC
+	}
+
+	return c.Source + "@" + tz.String()
+}
+
+func (c *Cache) GetLocation(ctx context.Context) (*time.Location, error) {
+	if c.Location != nil {
+		return c.Location, nil
+	}
+
+	return LocationOf(ctx, c.Weather), nil
+}
+
+// Keeps each day as a JSON file in Dir/<source>/<date>.json
+type DiskCache struct {
+	Dir string
+}
+
+func NewDiskCache(dir string) *DiskCache {
+	return &DiskCache{
+		Dir: dir,
+	}
+}
+
+func (d *DiskCache) path(source string, date string) string {
+	// Sources look like synoptic/klnk so keep them to a single directory
+	source = strings.NewReplacer("/", "_", "\\", "_", "..", "_", " ", "_").Replace(source)
+
+	return filepath.Join(d.Dir, source, date+".json")
+}
+
+func (d *DiskCache) Get(source string, date string) (*WeatherInfo, error) {
+	contents, err := ioutil.ReadFile(d.path(source, date))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var weatherInfo WeatherInfo
+	err = json.Unmarshal(contents, &weatherInfo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &weatherInfo, nil
+}
+
+func (d *DiskCache) Put(source string, date string, weatherInfo *WeatherInfo) error {
+	path := d.path(source, date)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(weatherInfo, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	// Write then rename so a concurrent reader never sees a partial file
+	tmp := path + ".tmp"
+
+	if err := ioutil.WriteFile(tmp, contents, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,92 @@
+package weather
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	chicago, _ := time.LoadLocation("America/Chicago")
+
+	provider := &fakeProvider{name: "synoptic/klnk", info: &WeatherInfo{
+		Date: time.Date(2023, 1, 10, 0, 0, 0, 0, chicago),
+		High: 55.4,
+		Low:  24.8,
+	}}
+
+	cache := NewCache(provider, NewDiskCache(t.TempDir()))
+	cache.Location = chicago
+
+	for i := 0; i < 2; i++ {
+		info, err := cache.GetDailyWeather(context.Background(), time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.High != 55.4 || !info.Date.Equal(provider.info.Date) {
+			t.Errorf("Unexpected weather %+v", info)
+		}
+	}
+
+	if provider.calls != 1 {
+		t.Errorf("Expected the provider to be called once, got %d", provider.calls)
+	}
+}
+
+func TestCacheSkipsIncompleteDays(t *testing.T) {
+	now := time.Now()
+
+	provider := &fakeProvider{name: "today", info: &WeatherInfo{
+		Date: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+	}}
+
+	cache := NewCache(provider, NewDiskCache(t.TempDir()))
+
+	cache.GetDailyWeather(context.Background(), now)
+	cache.GetDailyWeather(context.Background(), now)
+
+	if provider.calls != 2 {
+		t.Errorf("A day that is not over should not be cached, provider called %d times", provider.calls)
+	}
+}
+
+type keyedProvider struct {
+	fakeProvider
+	key string
+}
+
+func (k *keyedProvider) CacheKey() string {
+	return k.key
+}
+
+func TestCacheKeys(t *testing.T) {
+	store := NewDiskCache(t.TempDir())
+	date := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	lincoln := &keyedProvider{fakeProvider{name: "open-meteo", info: &WeatherInfo{Date: date, High: 30}}, "open-meteo/40.8,-96.7"}
+	denver := &keyedProvider{fakeProvider{name: "open-meteo", info: &WeatherInfo{Date: date, High: 50}}, "open-meteo/39.7,-105.0"}
+
+	NewCache(lincoln, store).GetDailyWeather(context.Background(), date)
+	info, _ := NewCache(denver, store).GetDailyWeather(context.Background(), date)
+
+	if info.High != 50 || denver.calls != 1 {
+		t.Errorf("Expected providers with the same name but different keys not to share days, got %+v", info)
+	}
+}
+
+func TestCacheSkipsFallbackAnswers(t *testing.T) {
+	date := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	primary := &fakeProvider{name: "primary", err: context.DeadlineExceeded}
+	fallback := &fakeProvider{name: "fallback", info: &WeatherInfo{Date: date, High: 50}}
+
+	cache := NewCache(NewFailover(primary, fallback), NewDiskCache(t.TempDir()))
+
+	cache.GetDailyWeather(context.Background(), date)
+	cache.GetDailyWeather(context.Background(), date)
+
+	if primary.calls != 2 {
+		t.Errorf("Expected the primary to be asked again for a day a fallback answered, got %d calls", primary.calls)
+	}
+}
@@ -0,0 +1,114 @@
+package weather
+
+/**
+ * Readings from the sensors a station has besides its thermometer. Each is
+ * nil when the station did not report it.
+ */
+type Conditions struct {
+	// Inches of rain, or melted snow, since the previous reading
+	Precipitation *float64
+	// Inches of snow since the previous reading
+	Snowfall *float64
+	// Miles per hour
+	WindGust *float64
+	// Relative humidity in percent
+	Humidity *float64
+	// Fahrenheit
+	DewPoint *float64
+}
+
+func MillimetersToInches(millimeters float64) float64 {
+	return millimeters / 25.4
+}
+
+func CentimetersToInches(centimeters float64) float64 {
+	return centimeters / 2.54
+}
+
+func MetersPerSecondToMilesPerHour(metersPerSecond float64) float64 {
+	return metersPerSecond * 2.23694
+}
+
+func KilometersPerHourToMilesPerHour(kilometersPerHour float64) float64 {
+	return kilometersPerHour * 0.621371
+}
+
+func KnotsToMilesPerHour(knots float64) float64 {
+	return knots * 1.15078
+}
+
+/**
+ * Running totals of a day's conditions, for providers that read them one at
+ * a time instead of collecting them first. Measures that were never added
+ * are left nil.
+ */
+type ConditionsTotals struct {
+	precipitation measure
+	snowfall      measure
+	windGust      measure
+	humidity      measure
+	dewPoint      measure
+}
+
+func (t *ConditionsTotals) Add(c Conditions) {
+	t.precipitation.add(c.Precipitation)
+	t.snowfall.add(c.Snowfall)
+	t.windGust.add(c.WindGust)
+	t.humidity.add(c.Humidity)
+	t.dewPoint.add(c.DewPoint)
+}
+
+// Sets the day's precipitation and snowfall totals, max wind gust and mean humidity and dew point
+func (t *ConditionsTotals) Fill(w *WeatherInfo) {
+	w.Precipitation = t.precipitation.total()
+	w.Snowfall = t.snowfall.total()
+	w.WindGust = t.windGust.max()
+	w.Humidity = t.humidity.mean()
+	w.DewPoint = t.dewPoint.mean()
+}
+
+type measure struct {
+	count int
+	sum   float64
+	high  float64
+}
+
+func (m *measure) add(value *float64) {
+	if value == nil {
+		return
+	}
+
+	if m.count == 0 || *value > m.high {
+		m.high = *value
+	}
+
+	m.count++
+	m.sum += *value
+}
+
+func (m *measure) total() *float64 {
+	if m.count == 0 {
+		return nil
+	}
+
+	total := m.sum
+	return &total
+}
+
+func (m *measure) max() *float64 {
+	if m.count == 0 {
+		return nil
+	}
+
+	high := m.high
+	return &high
+}
+
+func (m *measure) mean() *float64 {
+	if m.count == 0 {
+		return nil
+	}
+
+	mean := m.sum / float64(m.count)
+	return &mean
+}
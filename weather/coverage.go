@@ -0,0 +1,104 @@
+package weather
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// How well a day's observations cover the day
+type Coverage struct {
+	Observations int
+	// Longest stretch without an observation, including from the start of
+	// the day to the first one and from the last one to the end of the day
+	LargestGap time.Duration
+	// Percent of the day's hours with at least one observation
+	Percent float64
+}
+
+// Limits below which a day is considered incomplete. Zero values are not checked.
+type CoverageThresholds struct {
+	MinObservations int
+	MaxGap          time.Duration
+	MinPercent      float64
+}
+
+var DefaultCoverageThresholds = CoverageThresholds{
+	MinObservations: 12,
+	MaxGap:          time.Hour * 3,
+	MinPercent:      75,
+}
+
+/**
+ * Measures how well the observations cover the day from start to end
+ */
+func MeasureCoverage(start time.Time, end time.Time, observations []Observation) *Coverage {
+	times := []time.Time{}
+
+	for _, observation := range observations {
+		if !observation.Time.Before(start) && observation.Time.Before(end) {
+			times = append(times, observation.Time)
+		}
+	}
+
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+
+	coverage := &Coverage{
+		Observations: len(times),
+	}
+
+	previous := start
+	hours := map[int]bool{}
+
+	for _, t := range times {
+		if gap := t.Sub(previous); gap > coverage.LargestGap {
+			coverage.LargestGap = gap
+		}
+
+		previous = t
+		hours[int(t.Sub(start)/time.Hour)] = true
+	}
+
+	if gap := end.Sub(previous); gap > coverage.LargestGap {
+		coverage.LargestGap = gap
+	}
+
+	// Days are 23 or 25 hours long when daylight saving time starts or ends
+	totalHours := int((end.Sub(start) + time.Hour - 1) / time.Hour)
+
+	if totalHours > 0 {
+		coverage.Percent = 100 * float64(len(hours)) / float64(totalHours)
+	}
+
+	return coverage
+}
+
+/**
+ * Returns why the day is incomplete, or nothing when it is complete. Days
+ * from providers that only report daily values have no coverage and are
+ * always complete.
+ */
+func (c CoverageThresholds) Check(weatherInfo *WeatherInfo) []string {
+	coverage := weatherInfo.Coverage
+	problems := []string{}
+
+	if coverage == nil {
+		return problems
+	}
+
+	if c.MinObservations > 0 && coverage.Observations < c.MinObservations {
+		problems = append(problems, fmt.Sprintf("only %d observations", coverage.Observations))
+	}
+
+	if c.MaxGap > 0 && coverage.LargestGap > c.MaxGap {
+		problems = append(problems, fmt.Sprintf("a %s gap in observations", coverage.LargestGap.Round(time.Minute)))
+	}
+
+	if c.MinPercent > 0 && coverage.Percent < c.MinPercent {
+		problems = append(problems, fmt.Sprintf("only %.0f%% of the day covered", coverage.Percent))
+	}
+
+	return problems
+}
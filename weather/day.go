@@ -0,0 +1,48 @@
+package weather
+
+import "time"
+
+/**
+ * A local calendar day as the half-open interval [Start, End). Days are 23 or
+ * 25 hours long when daylight saving time starts or ends, so End is always the
+ * next day's midnight rather than Start plus 24 hours.
+ */
+type Day struct {
+	Start time.Time
+	End   time.Time
+}
+
+// The calendar day t falls on in tz
+func DayOf(t time.Time, tz *time.Location) Day {
+	local := t.In(tz)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, tz)
+
+	return Day{
+		Start: start,
+		End:   time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, tz),
+	}
+}
+
+// The calendar day in tz with the same year, month and day as date
+func CalendarDay(date time.Time, tz *time.Location) Day {
+	return DayOf(time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, tz), tz)
+}
+
+// The calendar day before the one t falls on in tz
+func PreviousDay(t time.Time, tz *time.Location) Day {
+	local := t.In(tz)
+
+	return DayOf(time.Date(local.Year(), local.Month(), local.Day()-1, 12, 0, 0, 0, tz), tz)
+}
+
+func (d Day) Contains(t time.Time) bool {
+	return !t.Before(d.Start) && t.Before(d.End)
+}
+
+func (d Day) Next() Day {
+	return DayOf(d.End, d.End.Location())
+}
+
+func (d Day) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
@@ -0,0 +1,55 @@
+package weather
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDay(t *testing.T) {
+	chicago, _ := time.LoadLocation("America/Chicago")
+
+	for _, test := range []struct {
+		now      time.Time
+		date     string
+		duration time.Duration
+	}{
+		{time.Date(2023, 1, 11, 10, 0, 0, 0, chicago), "2023-01-10", time.Hour * 24},
+		// Daylight saving time started at 2 AM on March 12th
+		{time.Date(2023, 3, 13, 0, 30, 0, 0, chicago), "2023-03-12", time.Hour * 23},
+		// and ended at 2 AM on November 5th
+		{time.Date(2023, 11, 6, 23, 59, 0, 0, chicago), "2023-11-05", time.Hour * 25},
+	} {
+		day := PreviousDay(test.now, chicago)
+
+		if day.Start.Format("2006-01-02 15:04") != test.date+" 00:00" || day.Duration() != test.duration {
+			t.Errorf("Expected the day before %s to be %s lasting %s, got %s lasting %s", test.now, test.date, test.duration, day.Start, day.Duration())
+		}
+
+		if day.Next().Start != day.End {
+			t.Errorf("Expected the next day to start when %s ends", day.Start)
+		}
+	}
+}
+
+func TestSummarizeWholeDay(t *testing.T) {
+	chicago, _ := time.LoadLocation("America/Chicago")
+	day := DayOf(time.Date(2023, 11, 5, 12, 0, 0, 0, chicago), chicago)
+
+	observations := []Observation{
+		{Time: day.Start.Add(-time.Second), Temperature: 10},
+		{Time: day.Start, Temperature: 30},
+		// The last reading of a 25 hour day
+		{Time: day.End.Add(-time.Second * 30), Temperature: 50},
+		{Time: day.End, Temperature: 90},
+	}
+
+	info, err := Summarize(day.Start, observations)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.High != 50 || info.Low != 30 {
+		t.Errorf("Expected readings from 00:00 through 23:59:30 to be used, got %v/%v", info.High, info.Low)
+	}
+}
@@ -0,0 +1,163 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Implemented by providers that can describe themselves in logs and messages
+type Named interface {
+	Name() string
+}
+
+func ProviderName(w Weather) string {
+	if named, ok := w.(Named); ok {
+		return named.Name()
+	}
+
+	return fmt.Sprintf("%T", w)
+}
+
+// Returned when every provider in a Failover failed
+type FailoverError struct {
+	Errors []error
+}
+
+func (e *FailoverError) Error() string {
+	failures := []string{}
+
+	for _, err := range e.Errors {
+		failures = append(failures, err.Error())
+	}
+
+	return "all weather providers failed: " + strings.Join(failures, "; ")
+}
+
+// A Weather that asks each of its providers in order until one answers
+type Failover struct {
+	Providers []Weather
+}
+
+func NewFailover(providers ...Weather) *Failover {
+	return &Failover{
+		Providers: providers,
+	}
+}
+
+func (f *Failover) Name() string {
+	names := []string{}
+
+	for _, provider := range f.Providers {
+		names = append(names, ProviderName(provider))
+	}
+
+	return strings.Join(names, " > ")
+}
+
+// The primary's, since only its answers are cached
+func (f *Failover) CacheKey() string {
+	if len(f.Providers) == 0 {
+		return f.Name()
+	}
+
+	return ProviderKey(f.Providers[0])
+}
+
+func (f *Failover) fromPrimary(weatherInfo *WeatherInfo) bool {
+	return len(f.Providers) > 0 && weatherInfo.Provider == ProviderName(f.Providers[0])
+}
+
+/**
+ * The timezone of the first provider that has one. Providers that fail to
+ * look theirs up are skipped, since the same outage is usually why the
+ * failover is needed.
+ */
+func (f *Failover) GetLocation(ctx context.Context) (*time.Location, error) {
+	failures := &FailoverError{}
+
+	for _, provider := range f.Providers {
+		localized, ok := provider.(Localized)
+
+		if !ok {
+			continue
+		}
+
+		name := ProviderName(provider)
+		tz, err := localized.GetLocation(ctx)
+
+		if err != nil {
+			log.Printf("Weather provider %s could not get its timezone: %s", name, err)
+			failures.Errors = append(failures.Errors, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+
+		if tz != nil {
+			return tz, nil
+		}
+	}
+
+	if len(failures.Errors) > 0 {
+		return nil, failures
+	}
+
+	return time.UTC, nil
+}
+
+func (f *Failover) GetDailyWeather(ctx context.Context, date time.Time) (*WeatherInfo, error) {
+	failures := &FailoverError{}
+
+	for _, provider := range f.Providers {
+		name := ProviderName(provider)
+		weatherInfo, err := provider.GetDailyWeather(ctx, date)
+
+		if err == nil && weatherInfo == nil {
+			err = fmt.Errorf("no weather info returned")
+		}
+
+		if err != nil {
+			log.Printf("Weather provider %s failed: %s", name, err)
+			failures.Errors = append(failures.Errors, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+
+		if weatherInfo.Provider == "" {
+			weatherInfo.Provider = name
+		}
+
+		log.Printf("Weather provided by %s", weatherInfo.Provider)
+
+		return weatherInfo, nil
+	}
+
+	return nil, failures
+}
+
+func (f *Failover) GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*WeatherInfo, error) {
+	failures := &FailoverError{}
+
+	for _, provider := range f.Providers {
+		name := ProviderName(provider)
+		weatherInfos, err := provider.GetDailyRange(ctx, from, to)
+
+		if err != nil {
+			log.Printf("Weather provider %s failed: %s", name, err)
+			failures.Errors = append(failures.Errors, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+
+		for _, weatherInfo := range weatherInfos {
+			if weatherInfo.Provider == "" {
+				weatherInfo.Provider = name
+			}
+		}
+
+		log.Printf("Weather for %d days provided by %s", len(weatherInfos), name)
+
+		return weatherInfos, nil
+	}
+
+	return nil, failures
+}
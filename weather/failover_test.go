@@ -0,0 +1,98 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeProvider struct {
+	name  string
+	info  *WeatherInfo
+	err   error
+	calls int
+}
+
+func (f *fakeProvider) Name() string {
+	return f.name
+}
+
+func (f *fakeProvider) GetDailyWeather(ctx context.Context, date time.Time) (*WeatherInfo, error) {
+	f.calls++
+	return f.info, f.err
+}
+
+func (f *fakeProvider) GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*WeatherInfo, error) {
+	return EachDay(ctx, from, to, time.UTC, f.GetDailyWeather)
+}
+
+func TestFailover(t *testing.T) {
+	down := &fakeProvider{name: "down", err: errors.New("unavailable")}
+	empty := &fakeProvider{name: "empty"}
+	up := &fakeProvider{name: "up", info: &WeatherInfo{High: 50}}
+	unused := &fakeProvider{name: "unused", info: &WeatherInfo{High: 10}}
+
+	failover := NewFailover(down, empty, up, unused)
+
+	info, err := failover.GetDailyWeather(context.Background(), time.Now())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Provider != "up" || info.High != 50 {
+		t.Errorf("Expected weather from up, got %+v", info)
+	}
+
+	if unused.calls != 0 {
+		t.Errorf("Providers after the one that answered should not be called")
+	}
+}
+
+func TestFailoverAllFail(t *testing.T) {
+	failover := NewFailover(
+		&fakeProvider{name: "a", err: errors.New("first")},
+		&fakeProvider{name: "b", err: errors.New("second")},
+	)
+
+	_, err := failover.GetDailyWeather(context.Background(), time.Now())
+
+	var failoverErr *FailoverError
+
+	if !errors.As(err, &failoverErr) || len(failoverErr.Errors) != 2 {
+		t.Fatalf("Expected both failures, got %v", err)
+	}
+
+	if err.Error() != "all weather providers failed: a: first; b: second" {
+		t.Errorf("Unexpected error %s", err)
+	}
+}
+
+type localizedProvider struct {
+	fakeProvider
+	location *time.Location
+	err      error
+}
+
+func (l *localizedProvider) GetLocation(ctx context.Context) (*time.Location, error) {
+	return l.location, l.err
+}
+
+func TestFailoverLocationSkipsFailures(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failover := NewFailover(
+		&localizedProvider{fakeProvider: fakeProvider{name: "down"}, err: errors.New("unavailable")},
+		&fakeProvider{name: "unlocalized"},
+		&localizedProvider{fakeProvider: fakeProvider{name: "up"}, location: denver},
+	)
+
+	if tz := LocationOf(context.Background(), failover); tz != denver {
+		t.Errorf("Expected the timezone of the first provider that answered, got %s", tz)
+	}
+}
@@ -0,0 +1,216 @@
+package weather
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// An observation that was left out of a day's summary and why
+type Rejection struct {
+	Observation
+	Reason string
+}
+
+/**
+ * Rejects implausible readings before they are summarized. Zero values
+ * disable a check.
+ */
+type Filter struct {
+	// Readings outside Min..Max degrees fahrenheit are rejected
+	Min float64
+	Max float64
+	// A reading that jumps away from both of its neighbors faster than this
+	// many degrees per hour, and back again, is a spike. Real changes, like
+	// a front coming through, move in one direction.
+	MaxChangePerHour float64
+	// Readings further than this many robust standard deviations (from the
+	// median absolute deviation) from the median of the readings within
+	// Window of them are rejected
+	MaxZScore float64
+	Window    time.Duration
+}
+
+var DefaultFilter = Filter{
+	Min:              -80,
+	Max:              135,
+	MaxChangePerHour: 60,
+	MaxZScore:        5,
+	Window:           time.Hour,
+}
+
+// Intervals shorter than this are treated as this long when computing rates
+// so two readings a minute apart don't look like a huge rate of change
+const minRateInterval = time.Minute * 10
+
+// The MAD is floored at this many degrees so a run of identical readings
+// doesn't make every small change an outlier
+const minDeviation = 1.0
+
+/**
+ * Returns the observations that passed every check, oldest first, and the ones
+ * that did not
+ */
+func (f Filter) Apply(observations []Observation) ([]Observation, []Rejection) {
+	sorted := make([]Observation, len(observations))
+	copy(sorted, observations)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	rejections := []Rejection{}
+	inRange := []Observation{}
+
+	for _, observation := range sorted {
+		temp := observation.Temperature
+
+		if math.IsNaN(temp) || (f.Min != 0 || f.Max != 0) && (temp < f.Min || temp > f.Max) {
+			rejections = append(rejections, Rejection{
+				Observation: observation,
+				Reason:      fmt.Sprintf("outside %.0f°..%.0f°", f.Min, f.Max),
+			})
+
+			continue
+		}
+
+		inRange = append(inRange, observation)
+	}
+
+	kept := inRange
+
+	if f.MaxChangePerHour > 0 {
+		kept, rejections = f.rejectSpikes(kept, rejections)
+	}
+
+	if f.MaxZScore > 0 && f.Window > 0 {
+		kept, rejections = f.rejectOutliers(kept, rejections)
+	}
+
+	return kept, rejections
+}
+
+func (f Filter) rejectSpikes(observations []Observation, rejections []Rejection) ([]Observation, []Rejection) {
+	kept := []Observation{}
+
+	for i, observation := range observations {
+		// Readings at either end only have one neighbor, so a jump there
+		// can't tell which of the two is wrong. They are left to the
+		// outlier check.
+		spike := false
+
+		if i > 0 && i < len(observations)-1 {
+			before := changePerHour(observations[i-1], observation)
+			after := changePerHour(observation, observations[i+1])
+			spike = math.Abs(before) > f.MaxChangePerHour && math.Abs(after) > f.MaxChangePerHour && (before > 0) != (after > 0)
+		}
+
+		if spike {
+			rejections = append(rejections, Rejection{
+				Observation: observation,
+				Reason:      fmt.Sprintf("changed faster than %.0f° per hour", f.MaxChangePerHour),
+			})
+
+			continue
+		}
+
+		kept = append(kept, observation)
+	}
+
+	return kept, rejections
+}
+
+func changePerHour(from Observation, to Observation) float64 {
+	interval := to.Time.Sub(from.Time)
+
+	if interval < minRateInterval {
+		interval = minRateInterval
+	}
+
+	return (to.Temperature - from.Temperature) / interval.Hours()
+}
+
+func (f Filter) rejectOutliers(observations []Observation, rejections []Rejection) ([]Observation, []Rejection) {
+	kept := []Observation{}
+	// The observations are sorted, so the neighbors within the window are a
+	// range that only moves forward
+	first, last := 0, 0
+
+	for _, observation := range observations {
+		for observation.Time.Sub(observations[first].Time) > f.Window {
+			first++
+		}
+
+		for last < len(observations) && observations[last].Time.Sub(observation.Time) <= f.Window {
+			last++
+		}
+
+		window := []float64{}
+
+		for _, neighbor := range observations[first:last] {
+			window = append(window, neighbor.Temperature)
+		}
+
+		center := median(window)
+		deviations := []float64{}
+
+		for _, temp := range window {
+			deviations = append(deviations, math.Abs(temp-center))
+		}
+
+		// 1.4826 * MAD estimates the standard deviation of normal data
+		deviation := 1.4826 * median(deviations)
+
+		if deviation < minDeviation {
+			deviation = minDeviation
+		}
+
+		zScore := math.Abs(observation.Temperature-center) / deviation
+
+		if zScore > f.MaxZScore {
+			rejections = append(rejections, Rejection{
+				Observation: observation,
+				Reason:      fmt.Sprintf("%.1f robust standard deviations from nearby readings", zScore),
+			})
+
+			continue
+		}
+
+		kept = append(kept, observation)
+	}
+
+	return kept, rejections
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
+
+// Writes every rejected observation to the run log
+func LogRejections(source string, rejections []Rejection) {
+	for _, rejection := range rejections {
+		log.Printf(
+			"%s: rejected %.1f° at %s: %s",
+			source,
+			rejection.Temperature,
+			rejection.Time.Format(time.RFC3339),
+			rejection.Reason,
+		)
+	}
+}
@@ -0,0 +1,91 @@
+package weather
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilter(t *testing.T) {
+	start := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	observations := []Observation{}
+
+	// A reading every 5 minutes until a front comes through at noon
+	for minutes := 0; minutes < 24*60; minutes += 5 {
+		temp := 50.0
+
+		if minutes >= 12*60 {
+			temp = 20
+		}
+
+		observations = append(observations, Observation{Time: start.Add(time.Minute * time.Duration(minutes)), Temperature: temp})
+	}
+
+	// A sensor glitch, a one reading spike and a reading that wandered off
+	observations[10].Temperature = 140
+	observations[30].Temperature = 75
+	observations = append(observations, Observation{Time: start.Add(time.Hour*18 + time.Minute*2), Temperature: 40})
+
+	kept, rejections := DefaultFilter.Apply(observations)
+
+	if len(rejections) != 3 {
+		t.Fatalf("Expected 3 rejections, got %v", rejections)
+	}
+
+	rejected := map[time.Time]bool{}
+
+	for _, rejection := range rejections {
+		rejected[rejection.Time] = true
+	}
+
+	for _, i := range []int{10, 30, len(observations) - 1} {
+		if !rejected[observations[i].Time] {
+			t.Errorf("Expected the reading at %s to be rejected", observations[i].Time)
+		}
+	}
+
+	if len(kept) != len(observations)-3 {
+		t.Errorf("Expected the front to be kept, kept %d of %d", len(kept), len(observations))
+	}
+
+	for i := 1; i < len(kept); i++ {
+		if kept[i].Time.Before(kept[i-1].Time) {
+			t.Fatalf("Expected kept readings to be in order")
+		}
+	}
+}
+
+func TestFilterSpikeNextToFirstReading(t *testing.T) {
+	start := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	observations := []Observation{}
+
+	for minutes := 0; minutes < 60; minutes += 5 {
+		observations = append(observations, Observation{Time: start.Add(time.Minute * time.Duration(minutes)), Temperature: 50})
+	}
+
+	observations[1].Temperature = 120
+
+	kept, rejections := DefaultFilter.Apply(observations)
+
+	if len(rejections) != 1 || !rejections[0].Time.Equal(observations[1].Time) {
+		t.Fatalf("Expected only the spike at 00:05 to be rejected, got %v", rejections)
+	}
+
+	if len(kept) != len(observations)-1 || !kept[0].Time.Equal(start) {
+		t.Errorf("Expected the first reading to be kept, kept %v", kept)
+	}
+}
+
+func TestFilterDisabled(t *testing.T) {
+	start := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	observations := []Observation{
+		{Time: start, Temperature: 30},
+		{Time: start.Add(time.Minute * 5), Temperature: 140},
+		{Time: start.Add(time.Minute * 10), Temperature: 30},
+	}
+
+	kept, rejections := Filter{}.Apply(observations)
+
+	if len(kept) != 3 || len(rejections) != 0 {
+		t.Errorf("Expected an empty filter to keep everything, rejected %v", rejections)
+	}
+}
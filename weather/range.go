@@ -0,0 +1,123 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+var ErrNoDays = errors.New("no weather for any day in the range")
+
+/**
+ * Returns the start of every calendar day from first through last in tz.
+ * Only the year, month and day of first and last are used.
+ */
+func Days(first time.Time, last time.Time, tz *time.Location) []time.Time {
+	days := []time.Time{}
+	day := CalendarDay(first, tz)
+	lastDay := CalendarDay(last, tz)
+
+	for !day.Start.After(lastDay.Start) {
+		days = append(days, day.Start)
+		day = day.Next()
+	}
+
+	return days
+}
+
+/**
+ * Splits observations by the given local days, which must be in order, and
+ * summarizes each one. Days without any observations are left out.
+ */
+func SummarizeDays(days []time.Time, observations []Observation) []*WeatherInfo {
+	byDay := make([][]Observation, len(days))
+
+	for _, observation := range observations {
+		// Index of the first day starting after the observation
+		i := sort.Search(len(days), func(i int) bool {
+			return days[i].After(observation.Time)
+		}) - 1
+
+		if i < 0 {
+			continue
+		}
+
+		if DayOf(days[i], days[i].Location()).Contains(observation.Time) {
+			byDay[i] = append(byDay[i], observation)
+		}
+	}
+
+	weatherInfos := []*WeatherInfo{}
+
+	for i, start := range days {
+		if len(byDay[i]) == 0 {
+			log.Printf("No observations for %s", start.Format("Jan 2 2006"))
+			continue
+		}
+
+		weatherInfo, _ := Summarize(start, byDay[i])
+		weatherInfos = append(weatherInfos, weatherInfo)
+	}
+
+	return weatherInfos
+}
+
+/**
+ * Returns one summary per day from first through last by asking get for each
+ * day in turn, for providers that can't fetch a range any faster. Days that
+ * fail are logged and left out.
+ */
+func EachDay(
+	ctx context.Context,
+	first time.Time,
+	last time.Time,
+	tz *time.Location,
+	get func(ctx context.Context, date time.Time) (*WeatherInfo, error),
+) ([]*WeatherInfo, error) {
+	weatherInfos := []*WeatherInfo{}
+
+	for _, day := range Days(first, last, tz) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		weatherInfo, err := get(ctx, day)
+
+		if err != nil {
+			log.Printf("Could not get weather for %s: %s", day.Format("Jan 2 2006"), err)
+			continue
+		}
+
+		weatherInfos = append(weatherInfos, weatherInfo)
+	}
+
+	if len(weatherInfos) == 0 {
+		return nil, fmt.Errorf("%w %s - %s", ErrNoDays, first.Format("Jan 2 2006"), last.Format("Jan 2 2006"))
+	}
+
+	return weatherInfos, nil
+}
+
+// Implemented by providers that know which timezone their days are in
+type Localized interface {
+	GetLocation(ctx context.Context) (*time.Location, error)
+}
+
+// The timezone w's days are in, UTC when it doesn't say
+func LocationOf(ctx context.Context, w Weather) *time.Location {
+	if localized, ok := w.(Localized); ok {
+		if tz, err := localized.GetLocation(ctx); err == nil && tz != nil {
+			return tz
+		}
+	}
+
+	return time.UTC
+}
+
+// The date of the day before now in w's timezone, ex: to send yesterday's weather
+func Yesterday(ctx context.Context, w Weather, now time.Time) time.Time {
+	return PreviousDay(now, LocationOf(ctx, w)).Start
+}
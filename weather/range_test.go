@@ -0,0 +1,67 @@
+package weather
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type dailyProvider struct {
+	asked []time.Time
+}
+
+func (d *dailyProvider) GetDailyWeather(ctx context.Context, date time.Time) (*WeatherInfo, error) {
+	d.asked = append(d.asked, date)
+
+	return &WeatherInfo{
+		Date: CalendarDay(date, time.UTC).Start,
+		High: float64(date.Day()),
+	}, nil
+}
+
+func (d *dailyProvider) GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*WeatherInfo, error) {
+	return EachDay(ctx, from, to, time.UTC, d.GetDailyWeather)
+}
+
+func TestEachDay(t *testing.T) {
+	provider := &dailyProvider{}
+	ctx := context.Background()
+
+	weatherInfos, err := provider.GetDailyRange(ctx, time.Date(2023, 1, 30, 0, 0, 0, 0, time.UTC), time.Date(2023, 2, 2, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(weatherInfos) != 4 || weatherInfos[0].High != 30 || weatherInfos[3].High != 2 {
+		t.Errorf("Expected Jan 30 through Feb 2, got %d days", len(weatherInfos))
+	}
+
+	// A cache in front only asks for the days it does not have
+	cache := NewCache(provider, NewDiskCache(t.TempDir()))
+
+	cache.GetDailyRange(ctx, time.Date(2023, 1, 30, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC))
+	provider.asked = nil
+
+	weatherInfos, err = cache.GetDailyRange(ctx, time.Date(2023, 1, 30, 0, 0, 0, 0, time.UTC), time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(weatherInfos) != 3 || len(provider.asked) != 1 {
+		t.Errorf("Expected 3 days with 1 fetched, got %d days with %d fetched", len(weatherInfos), len(provider.asked))
+	}
+}
+
+func TestEachDayCancelled(t *testing.T) {
+	provider := &dailyProvider{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := provider.GetDailyRange(ctx, time.Date(2023, 1, 30, 0, 0, 0, 0, time.UTC), time.Date(2023, 2, 2, 0, 0, 0, 0, time.UTC))
+
+	if err != context.Canceled || len(provider.asked) != 0 {
+		t.Errorf("Expected a cancelled range to stop, got %v after %d days", err, len(provider.asked))
+	}
+}
@@ -0,0 +1,72 @@
+package weather
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNoObservations = errors.New("no observations for the day")
+
+// A single temperature reading in fahrenheit
+type Observation struct {
+	Time        time.Time
+	Temperature float64
+}
+
+/**
+ * Aggregates the observations taken during the local calendar day starting
+ * at date into the high, low and average temperature. Observations outside
+ * the day are ignored. Average is the sample mean, the time-weighted mean is
+ * in Averages.
+ */
+func Summarize(date time.Time, all []Observation) (*WeatherInfo, error) {
+	day := DayOf(date, date.Location())
+	observations := []Observation{}
+
+	for _, observation := range all {
+		if day.Contains(observation.Time) {
+			observations = append(observations, observation)
+		}
+	}
+
+	if len(observations) == 0 {
+		return nil, ErrNoObservations
+	}
+
+	total := 0.0
+	high := 0.0
+	low := 0.0
+
+	for i, observation := range observations {
+		temp := observation.Temperature
+
+		if i == 0 {
+			high = temp
+			low = temp
+		}
+
+		if temp > high {
+			high = temp
+		}
+
+		if temp < low {
+			low = temp
+		}
+
+		total += temp
+	}
+
+	avg := total / float64(len(observations))
+
+	return &WeatherInfo{
+		Date:    date,
+		High:    high,
+		Low:     low,
+		Average: avg,
+		Averages: map[AverageMethod]float64{
+			SampleMean:   avg,
+			TimeWeighted: TimeWeightedMean(observations),
+		},
+		Coverage: MeasureCoverage(day.Start, day.End, observations),
+	}, nil
+}
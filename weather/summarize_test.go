@@ -0,0 +1,96 @@
+package weather
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSummarizeAverages(t *testing.T) {
+	start := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	// Hourly readings at 30 degrees with a burst of SPECIs at 60 degrees
+	observations := []Observation{
+		{Time: start, Temperature: 30},
+		{Time: start.Add(time.Hour * 6), Temperature: 30},
+		{Time: start.Add(time.Hour*6 + time.Minute*5), Temperature: 60},
+		{Time: start.Add(time.Hour*6 + time.Minute*10), Temperature: 60},
+		{Time: start.Add(time.Hour*6 + time.Minute*15), Temperature: 60},
+		{Time: start.Add(time.Hour*6 + time.Minute*20), Temperature: 30},
+		{Time: start.Add(time.Hour * 12), Temperature: 30},
+	}
+
+	info, err := Summarize(start, observations)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprintf("%.2f", info.AverageFor(SampleMean)) != "42.86" {
+		t.Errorf("Unexpected sample mean %f", info.AverageFor(SampleMean))
+	}
+
+	if fmt.Sprintf("%.3f", info.AverageFor(TimeWeighted)) != "30.625" {
+		t.Errorf("Unexpected time-weighted mean %f", info.AverageFor(TimeWeighted))
+	}
+
+	if info.AverageFor(HighLowMean) != 45 {
+		t.Errorf("Unexpected nws average %f", info.AverageFor(HighLowMean))
+	}
+
+	if _, err := ParseAverageMethod("median"); err == nil {
+		t.Errorf("Expected an unknown average to be rejected")
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	start := time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)
+	observations := []Observation{}
+
+	// Every 5 minutes until 06:00, then one reading at 23:00
+	for minutes := 0; minutes < 6*60; minutes += 5 {
+		observations = append(observations, Observation{Time: start.Add(time.Minute * time.Duration(minutes)), Temperature: 30})
+	}
+
+	observations = append(observations, Observation{Time: start.Add(time.Hour * 23), Temperature: 30})
+
+	info, err := Summarize(start, observations)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coverage := info.Coverage
+
+	if coverage.Observations != 73 || coverage.LargestGap != time.Hour*17+time.Minute*5 || fmt.Sprintf("%.1f", coverage.Percent) != "29.2" {
+		t.Errorf("Unexpected coverage %+v", coverage)
+	}
+
+	problems := DefaultCoverageThresholds.Check(info)
+
+	if len(problems) != 2 {
+		t.Errorf("Expected a gap and percent problem, got %v", problems)
+	}
+
+	if _, err := Summarize(start, nil); err != ErrNoObservations {
+		t.Errorf("Expected ErrNoObservations, got %v", err)
+	}
+}
+
+func TestCoverageDaylightSaving(t *testing.T) {
+	chicago, _ := time.LoadLocation("America/Chicago")
+	start := time.Date(2023, 3, 12, 0, 0, 0, 0, chicago)
+	end := time.Date(2023, 3, 13, 0, 0, 0, 0, chicago)
+	observations := []Observation{}
+
+	for t := start; t.Before(end); t = t.Add(time.Hour) {
+		observations = append(observations, Observation{Time: t, Temperature: 30})
+	}
+
+	coverage := MeasureCoverage(start, end, observations)
+
+	// The day daylight saving time starts is 23 hours long
+	if coverage.Observations != 23 || coverage.Percent != 100 {
+		t.Errorf("Unexpected coverage %+v", coverage)
+	}
+}
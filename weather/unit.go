@@ -0,0 +1,82 @@
+package weather
+
+import (
+	"fmt"
+	"strings"
+)
+
+/**
+ * A temperature scale. WeatherInfo and Observation temperatures are always
+ * fahrenheit, providers convert from the unit their source uses and blankets
+ * convert to the unit they display.
+ */
+type Unit string
+
+const (
+	Fahrenheit Unit = "F"
+	Celsius    Unit = "C"
+	Kelvin     Unit = "K"
+)
+
+/**
+ * Accepts the unit's letter or name in any case, ex: C, celsius or the
+ * "Celsius" Synoptic reports. Defaults to fahrenheit.
+ */
+func ParseUnit(unit string) (Unit, error) {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "", "f", "fahrenheit":
+		return Fahrenheit, nil
+	case "c", "celsius", "celcius":
+		return Celsius, nil
+	case "k", "kelvin":
+		return Kelvin, nil
+	default:
+		return "", fmt.Errorf("unknown temperature unit %q, expected F, C or K", unit)
+	}
+}
+
+// Converts a temperature in this unit to fahrenheit
+func (u Unit) ToFahrenheit(temp float64) float64 {
+	switch u {
+	case Celsius:
+		return temp*1.8 + 32
+	case Kelvin:
+		return (temp-273.15)*1.8 + 32
+	default:
+		return temp
+	}
+}
+
+// Converts a temperature in fahrenheit to this unit
+func (u Unit) FromFahrenheit(temp float64) float64 {
+	switch u {
+	case Celsius:
+		return (temp - 32) / 1.8
+	case Kelvin:
+		return (temp-32)/1.8 + 273.15
+	default:
+		return temp
+	}
+}
+
+// Converts a difference between temperatures, like a departure from normal,
+// from fahrenheit to this unit
+func (u Unit) DifferenceFromFahrenheit(difference float64) float64 {
+	if u == Fahrenheit || u == "" {
+		return difference
+	}
+
+	return difference / 1.8
+}
+
+// How a temperature in this unit is labeled, ex: °F or K
+func (u Unit) Symbol() string {
+	switch u {
+	case Celsius:
+		return "°C"
+	case Kelvin:
+		return "K"
+	default:
+		return "°F"
+	}
+}
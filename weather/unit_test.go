@@ -0,0 +1,40 @@
+package weather
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestUnit(t *testing.T) {
+	for _, test := range []struct {
+		unit       string
+		fahrenheit float64
+		temp       float64
+	}{
+		{"F", 50, 50},
+		{"Celsius", 50, 10},
+		{"k", 32, 273.15},
+	} {
+		unit, err := ParseUnit(test.unit)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if fmt.Sprintf("%.2f", unit.FromFahrenheit(test.fahrenheit)) != fmt.Sprintf("%.2f", test.temp) {
+			t.Errorf("Expected %v°F to be %v %s, got %v", test.fahrenheit, test.temp, unit, unit.FromFahrenheit(test.fahrenheit))
+		}
+
+		if fmt.Sprintf("%.2f", unit.ToFahrenheit(test.temp)) != fmt.Sprintf("%.2f", test.fahrenheit) {
+			t.Errorf("Expected %v %s to be %v°F, got %v", test.temp, unit, test.fahrenheit, unit.ToFahrenheit(test.temp))
+		}
+	}
+
+	if Celsius.DifferenceFromFahrenheit(18) != 10 {
+		t.Errorf("Expected an 18°F difference to be 10°C")
+	}
+
+	if _, err := ParseUnit("rankine"); err == nil {
+		t.Errorf("Expected an unknown unit to be rejected")
+	}
+}
@@ -1,18 +1,56 @@
 package weather
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type WeatherInfo struct {
 	Date    time.Time
 	High    float64
 	Low     float64
 	Average float64
+	// The average by other definitions when the provider can compute them.
+	// See AverageFor
+	Averages map[AverageMethod]float64 `json:",omitempty"`
+	// Name of the provider the numbers came from
+	Provider string
+	// How well the observations covered the day. Nil for providers that
+	// report official daily values
+	Coverage *Coverage `json:",omitempty"`
+	// Totals in inches. Nil, like the other measures below, when the
+	// station has no such sensor or the provider doesn't report it
+	Precipitation *float64 `json:",omitempty"`
+	Snowfall      *float64 `json:",omitempty"`
+	// Strongest gust in miles per hour
+	WindGust *float64 `json:",omitempty"`
+	// Mean relative humidity in percent
+	Humidity *float64 `json:",omitempty"`
+	// Mean dew point in fahrenheit
+	DewPoint *float64 `json:",omitempty"`
+	// How the day compared to its climate normals. Nil for providers that
+	// don't report normals
+	Departure *Departure `json:",omitempty"`
+}
+
+// Degrees above the climate normal, negative when below. Nil when missing
+type Departure struct {
+	High    *float64 `json:",omitempty"`
+	Low     *float64 `json:",omitempty"`
+	Average *float64 `json:",omitempty"`
 }
 
 func CelciusToFahrenheit(celcius float64) float64 {
-	return (celcius * 1.8) + 32
+	return Celsius.ToFahrenheit(celcius)
 }
 
+/**
+ * A source of daily weather. Days are local calendar days in the provider's
+ * timezone and only the year, month and day of the dates given are used.
+ */
 type Weather interface {
-	GetPreviousDaysWeatherInfo(day time.Time) (*WeatherInfo, error)
+	GetDailyWeather(ctx context.Context, date time.Time) (*WeatherInfo, error)
+	// One summary per day from from through to. Days without data are left
+	// out.
+	GetDailyRange(ctx context.Context, from time.Time, to time.Time) ([]*WeatherInfo, error)
 }